@@ -40,9 +40,20 @@ const (
 	TokenURL     = EveBaseURL + "/token"
 	VerifyURL    = EveBaseURL + "/verify"
 	Scope        = "esi-location.read_location.v1"
-	APIBaseURL   = "https://esi.evetech.net/latest"
 )
 
+// APIBaseURL where ESI is, tests point it at a local server
+var APIBaseURL = "https://esi.evetech.net/latest"
+
+// Token states passed to OnTokenStateChange
+const (
+	TokenStateLoggedIn  = "logged_in"
+	TokenStateRefreshed = "refreshed"
+)
+
+// OnTokenStateChange is called after logging in and after every token refresh.
+var OnTokenStateChange func(state string)
+
 var codeChallenge string
 var codeVerifier string
 var Character CharacterInfo
@@ -169,6 +180,7 @@ func getCode(w http.ResponseWriter, r *http.Request) {
 		// Exchange authorization code for access token
 		setAccessTokens(code)
 		setCharacterInformation(Tokens.AccessToken)
+		notifyTokenState(TokenStateLoggedIn)
 		fmt.Fprintln(w, "Access Token Granted you can close this tab")
 	} else {
 		fmt.Fprintln(w, "No authorization code received.")
@@ -204,6 +216,7 @@ func setAccessTokens(code string) {
 	go func() {
 		for range time.Tick(time.Minute * 19) {
 			refreshTokens()
+			notifyTokenState(TokenStateRefreshed)
 		}
 	}()
 }
@@ -228,6 +241,12 @@ func setCharacterInformation(accessToken string) {
 	}
 }
 
+func notifyTokenState(state string) {
+	if OnTokenStateChange != nil {
+		OnTokenStateChange(state)
+	}
+}
+
 func isServerRunning(server *http.Server) bool {
 	// Send a request to the server and check if it's responding
 	client := &http.Client{Timeout: time.Second}
@@ -0,0 +1,192 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type SovereigntyInfo struct {
+	SystemID      int `json:"system_id"`
+	AllianceID    int `json:"alliance_id"`
+	CorporationID int `json:"corporation_id"`
+	FactionID     int `json:"faction_id"`
+}
+
+type NameInfo struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Category string `json:"category"`
+}
+
+type SystemKills struct {
+	SystemID  int `json:"system_id"`
+	ShipKills int `json:"ship_kills"`
+	PodKills  int `json:"pod_kills"`
+	NpcKills  int `json:"npc_kills"`
+}
+
+type SystemJumps struct {
+	SystemID  int `json:"system_id"`
+	ShipJumps int `json:"ship_jumps"`
+}
+
+type RegionInfo struct {
+	RegionID       int    `json:"region_id"`
+	Name           string `json:"name"`
+	Constellations []int  `json:"constellations"`
+}
+
+type ConstellationInfo struct {
+	ConstellationID int    `json:"constellation_id"`
+	Name            string `json:"name"`
+	RegionID        int    `json:"region_id"`
+	Systems         []int  `json:"systems"`
+}
+
+type SolarSystemInfo struct {
+	SystemID  int   `json:"system_id"`
+	Stargates []int `json:"stargates"`
+}
+
+type StargateInfo struct {
+	StargateID  int `json:"stargate_id"`
+	SystemID    int `json:"system_id"`
+	Destination struct {
+		StargateID int `json:"stargate_id"`
+		SystemID   int `json:"system_id"`
+	} `json:"destination"`
+}
+
+// maxNamesPerRequest is the most ids /universe/names/ accepts in one call.
+const maxNamesPerRequest = 1000
+
+// defaultCacheTime is used when ESI doesn't send a usable Expires header.
+const defaultCacheTime = time.Minute * 10
+
+// GetSovereigntyMap returns the public sovereignty map and when ESI will next update it.
+func GetSovereigntyMap() ([]SovereigntyInfo, time.Time, error) {
+	var sovereignty []SovereigntyInfo
+	expires, err := getPublicJSON("/sovereignty/map/", &sovereignty)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	return sovereignty, expires, nil
+}
+
+// GetNames resolves alliance, corporation and faction ids to names.
+func GetNames(ids []int) ([]NameInfo, error) {
+	var names []NameInfo
+	for start := 0; start < len(ids); start += maxNamesPerRequest {
+		end := start + maxNamesPerRequest
+		if end > len(ids) {
+			end = len(ids)
+		}
+		body, err := json.Marshal(ids[start:end])
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := http.Post(APIBaseURL+"/universe/names/", "application/json", bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+
+		var chunk []NameInfo
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("universe names returned %s", resp.Status)
+		}
+		err = json.NewDecoder(resp.Body).Decode(&chunk)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, chunk...)
+	}
+
+	return names, nil
+}
+
+// GetSystemKills returns kills in the last hour for every system with kills and when ESI will next update it.
+func GetSystemKills() ([]SystemKills, time.Time, error) {
+	var kills []SystemKills
+	expires, err := getPublicJSON("/universe/system_kills/", &kills)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	return kills, expires, nil
+}
+
+// GetSystemJumps returns jumps in the last hour for every system with jumps and when ESI will next update it.
+func GetSystemJumps() ([]SystemJumps, time.Time, error) {
+	var jumps []SystemJumps
+	expires, err := getPublicJSON("/universe/system_jumps/", &jumps)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	return jumps, expires, nil
+}
+
+// GetRegionIDs returns the id of every region.
+func GetRegionIDs() ([]int, error) {
+	var regionIDs []int
+	_, err := getPublicJSON("/universe/regions/", &regionIDs)
+	return regionIDs, err
+}
+
+// GetRegion returns a region's name and constellations.
+func GetRegion(regionID int) (RegionInfo, error) {
+	var region RegionInfo
+	_, err := getPublicJSON(fmt.Sprintf("/universe/regions/%d/", regionID), &region)
+	return region, err
+}
+
+// GetConstellation returns a constellation's name, region and solar systems.
+func GetConstellation(constellationID int) (ConstellationInfo, error) {
+	var constellation ConstellationInfo
+	_, err := getPublicJSON(fmt.Sprintf("/universe/constellations/%d/", constellationID), &constellation)
+	return constellation, err
+}
+
+// GetSolarSystem returns a solar system's stargates.
+func GetSolarSystem(systemID int) (SolarSystemInfo, error) {
+	var solarSystem SolarSystemInfo
+	_, err := getPublicJSON(fmt.Sprintf("/universe/systems/%d/", systemID), &solarSystem)
+	return solarSystem, err
+}
+
+// GetStargate returns a stargate and the system it leads to.
+func GetStargate(stargateID int) (StargateInfo, error) {
+	var stargate StargateInfo
+	_, err := getPublicJSON(fmt.Sprintf("/universe/stargates/%d/", stargateID), &stargate)
+	return stargate, err
+}
+
+// getPublicJSON decodes an unauthenticated ESI endpoint into v and returns the Expires time ESI sent with it.
+func getPublicJSON(path string, v any) (time.Time, error) {
+	resp, err := http.Get(APIBaseURL + path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return time.Time{}, fmt.Errorf("%s returned %s", path, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return time.Time{}, err
+	}
+
+	expires, err := http.ParseTime(resp.Header.Get("Expires"))
+	if err != nil || expires.Before(time.Now()) {
+		expires = time.Now().Add(defaultCacheTime)
+	}
+
+	return expires, nil
+}
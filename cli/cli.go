@@ -0,0 +1,174 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// command a subcommand, run gets the arguments after the subcommand name.
+type command struct {
+	usage string
+	run   func(args []string, stdout io.Writer) error
+}
+
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"range": {
+			usage: "range <system> [--profile capitals,supers] [--activity] [--format text|json|csv]",
+			run:   runRange,
+		},
+		"stagings": {
+			usage: "stagings list|lists [--list name] [--format text|json|csv] | stagings add|remove <system> [note] [--list name] | stagings new-list|delete-list <name> | stagings export [--list name] [--format json|csv|yaml|names] [--out file] | stagings import <file|-> [--list name] [--replace]",
+			run:   runStagings,
+		},
+		"route": {
+			usage: "route <from> <to> [--via jumps,gates,bridges] [--profile capitals] [--hull dreadnought] [--prefer fast|safe|fatigue] [--friendly list] [--bridges from:to,from:to] [--format text|json|csv]",
+			run:   runRoute,
+		},
+		"reach": {
+			usage: "reach <system> [system...] [--list name] [--profile capitals] [--region name] [--sov holder] [--format text|json|csv]",
+			run:   runReach,
+		},
+		"regions": {
+			usage: "regions [--format text|json|csv] | regions fetch",
+			run:   runRegions,
+		},
+		"coverage": {
+			usage: "coverage [--list name] [--profile capitals] [--format text|json|csv]",
+			run:   runCoverage,
+		},
+		"matrix": {
+			usage: "matrix [--list name] [--format text|json|csv] [--out file.csv]",
+			run:   runMatrix,
+		},
+		"cynos": {
+			usage: "cynos <origin> <destination> [destination...] [--profile capitals] [--friendly list] [--format text|json|csv]",
+			run:   runCynos,
+		},
+		"portal": {
+			usage: "portal <system> [--type titan|blops] [--mass million kg] [--format text|json|csv] | portal fuel [isotopes per LY per million kg] [--type titan|blops]",
+			run:   runPortal,
+		},
+		"fuel": {
+			usage: "fuel [--format text|json|csv] | fuel set [--race amarr|caldari|gallente|minmatar] [--conservation 0-5] [--jump-freighters 0-5] [--hull dreadnought,titan]",
+			run:   runFuel,
+		},
+		"gates": {
+			usage: "gates route|jumps <from> <to> [--format text|json|csv] | gates within <system> <jumps> [--format text|json|csv] | gates fetch [--out file.csv]",
+			run:   runGates,
+		},
+		"bridges": {
+			usage: "bridges list [--format text|json|csv] | bridges add <from> <to> [--owner name] [--note text] | bridges remove <system> | bridges import <file|-> [--owner name] [--replace] | bridges export [--out file]",
+			run:   runBridges,
+		},
+		"history": {
+			usage: "history [--character name] [--days 7] [--format text|json|csv] [--out file.csv] | history on|off | history keep <days> | history clear",
+			run:   runHistory,
+		},
+		"intel": {
+			usage: "intel | intel gates <jumps|off> | intel check <message> [--profile capitals] [--from system] [--format text|json|csv]",
+			run:   runIntel,
+		},
+		"webhooks": {
+			usage: "webhooks list [--format text|json|csv] | webhooks add <name> <url> [--type discord|slack|json] [--lists a,b] [--profiles capitals] [--characters name] [--events staging_entered_range] | webhooks remove|test <name> | webhooks receive [--addr localhost:8082]",
+			run:   runWebhooks,
+		},
+		"subscriptions": {
+			usage: "subscriptions list [--format text|json|csv] | subscriptions add <list> <url|file> [--every 60] [--type json|csv|yaml|names] | subscriptions remove|sync|log <list>",
+			run:   runSubscriptions,
+		},
+		"serve": {
+			usage: "serve [--addr localhost:8081] | serve token [new|clear]",
+			run:   runServe,
+		},
+		"help": {
+			usage: "help",
+			run: func(args []string, stdout io.Writer) error {
+				printUsage(stdout)
+				return nil
+			},
+		},
+	}
+}
+
+// errUsage the command was called wrong, the usage is printed instead of the error.
+var errUsage = errors.New("usage")
+
+// IsCommand true when name is a subcommand, anything else launches the GUI.
+func IsCommand(name string) bool {
+	_, exists := commands[name]
+	return exists
+}
+
+// Run runs a subcommand and returns the exit code for the process.
+func Run(args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 || !IsCommand(args[0]) {
+		printUsage(stderr)
+		return 2
+	}
+
+	cmd := commands[args[0]]
+	err := cmd.run(args[1:], stdout)
+	if errors.Is(err, errUsage) || errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(stderr, "Usage: Eve-Sonar", cmd.usage)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return 1
+	}
+
+	return 0
+}
+
+func printUsage(w io.Writer) {
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "Usage: Eve-Sonar <command> [arguments]")
+	fmt.Fprintln(w, "Run without a command to open the app.")
+	fmt.Fprintln(w, "Commands:")
+	for _, name := range names {
+		fmt.Fprintln(w, "  Eve-Sonar", commands[name].usage)
+	}
+}
+
+// parseFlags parses flags that can come before or after the positional arguments and returns the positional ones.
+func parseFlags(flags *flag.FlagSet, args []string) ([]string, error) {
+	flags.SetOutput(io.Discard)
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%w: %s", errUsage, err)
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// splitList splits a comma separated flag value, ignoring blanks.
+func splitList(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"github.com/sythe7448/Eve-Sonar/eveSolarSystems"
+	"github.com/sythe7448/Eve-Sonar/internal/testutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testSystems a small hand built map, Alpha to Delta are in a line 3 LY apart and gated in that order
+var testSystems = []testutil.System{
+	{ID: "30000001", Name: "Alpha", X: 0, Sec: -0.4},
+	{ID: "30000002", Name: "Bravo", X: 3, Sec: -0.3},
+	{ID: "30000003", Name: "Charlie", X: 6, Sec: -0.2},
+	{ID: "30000004", Name: "Delta", X: 30, Sec: -0.1},
+}
+
+var testStargates = [][2]string{
+	{"30000001", "30000002"},
+	{"30000002", "30000003"},
+	{"30000003", "30000004"},
+}
+
+func TestMain(m *testing.M) {
+	testutil.Main(m, testSystems, testStargates, eveSolarSystems.WaitForRangeChecks)
+}
+
+func TestParseFlags(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		positional []string
+		list       string
+		wantErr    error
+	}{
+		{"flags after the arguments", []string{"add", "Bravo", "--list", "Hostiles"}, []string{"add", "Bravo"}, "Hostiles", nil},
+		{"flags in between", []string{"add", "-list=Hostiles", "Bravo", "keepstar"}, []string{"add", "Bravo", "keepstar"}, "Hostiles", nil},
+		{"unknown flag", []string{"add", "--lsit", "Hostiles"}, nil, "", errUsage},
+		{"help", []string{"-h"}, nil, "", flag.ErrHelp},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			flags := flag.NewFlagSet("test", flag.ContinueOnError)
+			list := flags.String("list", "", "")
+			positional, err := parseFlags(flags, test.args)
+			if !errors.Is(err, test.wantErr) || (test.wantErr == nil && err != nil) {
+				t.Fatalf("got %v, want %v", err, test.wantErr)
+			}
+			if !reflect.DeepEqual(positional, test.positional) || *list != test.list {
+				t.Errorf("got %q and list %q, want %q and %q", positional, *list, test.positional, test.list)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	// In order, the later commands see the stagings added by the earlier ones
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{"add with a note", []string{"stagings", "add", "bravo", "keepstar", "--list", "Hostiles"}, 0, "Added Bravo\n", ""},
+		{"list as csv", []string{"stagings", "list", "--format", "csv", "--list", "hostiles"}, 0, "List,System,Note\nHostiles,Bravo,keepstar\n", ""},
+		{"list as text", []string{"stagings", "list", "--list", "Hostiles"}, 0, "List      System  Note\nHostiles  Bravo   keepstar\n", ""},
+		{"gate jumps", []string{"gates", "jumps", "Alpha", "Delta"}, 0, "Alpha to Delta is 3 gate jumps\n", ""},
+		{"unknown system", []string{"stagings", "add", "Nowhere"}, 1, "", "Error: unknown system"},
+		{"unknown format", []string{"stagings", "lists", "--format", "xml"}, 1, "", `Error: unknown format "xml"`},
+		{"missing argument", []string{"range"}, 2, "", "Usage: Eve-Sonar range <system>"},
+		{"unknown flag", []string{"stagings", "list", "--nope"}, 2, "", "Usage: Eve-Sonar stagings"},
+		{"unknown command", []string{"launch"}, 2, "", "Usage: Eve-Sonar <command> [arguments]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := Run(test.args, &stdout, &stderr)
+			if code != test.code || stdout.String() != test.stdout || !strings.HasPrefix(stderr.String(), test.stderr) {
+				t.Errorf("got %d %q %q, want %d %q and stderr starting %q", code, stdout.String(), stderr.String(), test.code, test.stdout, test.stderr)
+			}
+		})
+	}
+}
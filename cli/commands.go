@@ -0,0 +1,371 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"github.com/sythe7448/Eve-Sonar/eveSolarSystems"
+	"github.com/sythe7448/Eve-Sonar/httpapi"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+func runRange(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("range", flag.ContinueOnError)
+	profile := flags.String("profile", "", "range profiles to check, comma separated, defaults to all")
+	activity := flags.Bool("activity", false, "fetch last hour kills and jumps from ESI first")
+	format := flags.String("format", formatText, "output format")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errUsage
+	}
+
+	solarSystem, err := findSystem(positional[0])
+	if err != nil {
+		return err
+	}
+	profiles, err := findProfiles(*profile)
+	if err != nil {
+		return err
+	}
+	if *activity {
+		if _, err := eveSolarSystems.RefreshSystemKills(); err != nil {
+			return err
+		}
+		if _, err := eveSolarSystems.RefreshSystemJumps(); err != nil {
+			return err
+		}
+	}
+
+	results := eveSolarSystems.GetRangeResults(solarSystem, profiles)
+	output := table{headers: []string{"Profile", "System", "Sec", "Region", "List", "Owner", "Sov", "LY", "Gates", "Isotopes", "Ship Kills", "Pod Kills", "NPC Kills", "Jumps"}}
+	for _, result := range results {
+		for _, staging := range result.Stagings {
+			output.rows = append(output.rows, []string{
+				result.Profile,
+				staging.System.Name,
+				strconv.FormatFloat(eveSolarSystems.DisplaySecurity(staging.System.Sec), 'f', 1, 64),
+				staging.Region,
+				staging.List,
+				staging.Owner,
+				staging.Sov,
+				formatLightYears(staging.LightYears),
+				eveSolarSystems.FormatGateJumps(staging.GateJumps),
+				strconv.Itoa(staging.Fuel.Isotopes),
+				strconv.Itoa(staging.Activity.ShipKills),
+				strconv.Itoa(staging.Activity.PodKills),
+				strconv.Itoa(staging.Activity.NpcKills),
+				strconv.Itoa(staging.Activity.Jumps),
+			})
+		}
+	}
+
+	return writeOutput(stdout, *format, results, output)
+}
+
+func runStagings(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("stagings", flag.ContinueOnError)
+	format := flags.String("format", formatText, "output format")
+	list := flags.String("list", "", "staging list, defaults to every list for list and Default otherwise")
+	out := flags.String("out", "", "file to export to, defaults to stdout")
+	replace := flags.Bool("replace", false, "make the list exactly what is imported instead of merging")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		return errUsage
+	}
+
+	switch positional[0] {
+	case "list":
+		if len(positional) != 1 {
+			return errUsage
+		}
+		listName := ""
+		if *list != "" {
+			found := false
+			if listName, found = eveSolarSystems.FindStagingList(*list); !found {
+				return fmt.Errorf("unknown staging list %q", *list)
+			}
+		}
+		entries := []eveSolarSystems.StagingEntry{}
+		output := table{headers: []string{"List", "System", "Note"}}
+		for _, entry := range eveSolarSystems.GetAllStagings() {
+			if listName == "" || entry.List == listName {
+				entries = append(entries, entry)
+				output.rows = append(output.rows, []string{entry.List, entry.System, entry.Note})
+			}
+		}
+		return writeOutput(stdout, *format, entries, output)
+	case "lists":
+		if len(positional) != 1 {
+			return errUsage
+		}
+		lists := eveSolarSystems.GetStagingListNames()
+		output := table{headers: []string{"List"}}
+		for _, listName := range lists {
+			output.rows = append(output.rows, []string{listName})
+		}
+		return writeOutput(stdout, *format, lists, output)
+	case "add":
+		if len(positional) < 2 {
+			return errUsage
+		}
+		listName := *list
+		if listName == "" {
+			listName = eveSolarSystems.DefaultStagingList
+		}
+		solarSystem, err := eveSolarSystems.AddStagingSystem(listName, positional[1], strings.Join(positional[2:], " "))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Added %s\n", solarSystem.Name)
+		return nil
+	case "remove":
+		if len(positional) != 2 {
+			return errUsage
+		}
+		if err := eveSolarSystems.RemoveStagingSystem(*list, positional[1]); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Removed %s\n", positional[1])
+		return nil
+	case "export":
+		if len(positional) != 1 {
+			return errUsage
+		}
+		return exportStagings(*list, *format, *out, stdout)
+	case "import":
+		if len(positional) != 2 {
+			return errUsage
+		}
+		return importStagings(*list, positional[1], *format, *replace, stdout)
+	case "new-list":
+		if len(positional) != 2 {
+			return errUsage
+		}
+		listName, err := eveSolarSystems.CreateStagingList(positional[1])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Added list %s\n", listName)
+		return nil
+	case "delete-list":
+		if len(positional) != 2 {
+			return errUsage
+		}
+		if err := eveSolarSystems.DeleteStagingList(positional[1]); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Deleted list %s\n", positional[1])
+		return nil
+	}
+
+	return errUsage
+}
+
+func runRoute(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("route", flag.ContinueOnError)
+	profile := flags.String("profile", "Capitals", "range profile to jump with")
+	hull := flags.String("hull", "", "hull jumping for the fuel estimate, defaults to the one saved with fuel set")
+	via := flags.String("via", "jumps", "comma separated jumps, gates and bridges")
+	prefer := flags.String("prefer", string(eveSolarSystems.RouteFastest), "fast, safe or fatigue")
+	bridges := flags.String("bridges", "", "comma separated jump bridges as from:to, on top of the saved ones")
+	friendly := flags.String("friendly", "", "staging list of systems safe routes land cyno jumps in")
+	format := flags.String("format", formatText, "output format")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 2 {
+		return errUsage
+	}
+
+	from, err := findSystem(positional[0])
+	if err != nil {
+		return err
+	}
+	to, err := findSystem(positional[1])
+	if err != nil {
+		return err
+	}
+
+	friendlySystems, err := findListSystems(*friendly)
+	if err != nil {
+		return err
+	}
+	options := eveSolarSystems.RouteOptions{Hull: *hull, Friendly: friendlySystems}
+	for _, travelType := range splitList(*via) {
+		switch strings.ToLower(travelType) {
+		case "jumps", "jump":
+			options.Profile = *profile
+		case "gates", "gate":
+			options.Gates = true
+		case "bridges", "bridge":
+			options.JumpBridges = eveSolarSystems.GetJumpBridges()
+			for _, pair := range splitList(*bridges) {
+				bridgeFrom, bridgeTo, found := strings.Cut(pair, ":")
+				if !found {
+					return fmt.Errorf("jump bridge %q isn't from:to", pair)
+				}
+				options.JumpBridges = append(options.JumpBridges, eveSolarSystems.JumpBridge{
+					From: strings.TrimSpace(bridgeFrom),
+					To:   strings.TrimSpace(bridgeTo),
+				})
+			}
+		default:
+			return fmt.Errorf("unknown travel type %q, use jumps, gates or bridges", travelType)
+		}
+	}
+	preference, found := eveSolarSystems.FindRoutePreference(*prefer)
+	if !found {
+		return fmt.Errorf("unknown route preference %q, use fast, safe or fatigue", *prefer)
+	}
+	options.Prefer = preference
+
+	plan, err := eveSolarSystems.PlanRoute(from, to, options)
+	if err != nil {
+		return err
+	}
+
+	output := table{headers: []string{"Step", "Type", "From", "To", "Sec", "LY", "Isotopes", "Bridge owner"}}
+	for i, step := range plan.Steps {
+		var isotopes, bridgeOwner string
+		if step.Fuel != nil {
+			isotopes = strconv.Itoa(step.Fuel.Isotopes)
+		}
+		if step.Bridge != nil {
+			bridgeOwner = step.Bridge.Owner
+		}
+		output.rows = append(output.rows, []string{
+			strconv.Itoa(i + 1),
+			string(step.Type),
+			step.From.Name,
+			step.To.Name,
+			strconv.FormatFloat(eveSolarSystems.DisplaySecurity(step.To.Sec), 'f', 1, 64),
+			formatLightYears(step.LightYears),
+			isotopes,
+			bridgeOwner,
+		})
+	}
+
+	if err := writeOutput(stdout, *format, plan, output); err != nil {
+		return err
+	}
+	if strings.EqualFold(*format, formatText) {
+		fmt.Fprintf(stdout, "%d jumps (%s LY), %d gates, %d bridges, about %.0f minutes\n",
+			plan.Jumps, formatLightYears(plan.LightYears), plan.Gates, plan.Bridges, plan.Minutes)
+		if plan.Fuel != nil {
+			fmt.Fprintf(stdout, "%s burns %d %s, %.0f m3\n", plan.Fuel.Hull, plan.Fuel.Isotopes, plan.Fuel.Isotope, plan.Fuel.Volume)
+		}
+	}
+	return nil
+}
+
+func runServe(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	address := flags.String("addr", eveSolarSystems.GetLocalAPIAddress(), "address to listen on")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 && positional[0] == "token" {
+		return runServeToken(positional[1:], stdout)
+	}
+	if len(positional) != 0 {
+		return errUsage
+	}
+
+	eveSolarSystems.StartWebhooks()
+	eveSolarSystems.StartSubscriptions()
+	fmt.Fprintf(stdout, "Local API listening on http://%s, spec at /openapi.yaml\n", *address)
+	return http.ListenAndServe(*address, httpapi.NewHandler())
+}
+
+// runServeToken shows, replaces or drops the token changes over the local API need.
+func runServeToken(args []string, stdout io.Writer) error {
+	if len(args) > 1 {
+		return errUsage
+	}
+	action := ""
+	if len(args) == 1 {
+		action = args[0]
+	}
+	switch action {
+	case "":
+		if token := eveSolarSystems.GetLocalAPIToken(); token != "" {
+			fmt.Fprintln(stdout, token)
+			return nil
+		}
+		fmt.Fprintln(stdout, "Changes over the local API need no token")
+	case "new":
+		token, err := eveSolarSystems.NewLocalAPIToken()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, token)
+	case "clear":
+		if err := eveSolarSystems.ClearLocalAPIToken(); err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, "Changes over the local API need no token")
+	default:
+		return errUsage
+	}
+	return nil
+}
+
+// findListSystems the systems of a staging list, none when list is blank.
+func findListSystems(list string) ([]eveSolarSystems.SolarSystem, error) {
+	if list == "" {
+		return nil, nil
+	}
+	listName, found := eveSolarSystems.FindStagingList(list)
+	if !found {
+		return nil, fmt.Errorf("unknown staging list %q", list)
+	}
+	var systems []eveSolarSystems.SolarSystem
+	for system := range eveSolarSystems.GetStagingList(listName) {
+		solarSystem, err := findSystem(system)
+		if err != nil {
+			return nil, err
+		}
+		systems = append(systems, solarSystem)
+	}
+	return systems, nil
+}
+
+func findSystem(name string) (eveSolarSystems.SolarSystem, error) {
+	solarSystem, found := eveSolarSystems.FindSystemByName(name)
+	if !found {
+		return eveSolarSystems.SolarSystem{}, fmt.Errorf("unknown system %q", name)
+	}
+	return solarSystem, nil
+}
+
+// findProfiles matches a comma separated list of range profiles, an empty list means every profile.
+func findProfiles(value string) ([]string, error) {
+	names := splitList(value)
+	if len(names) == 0 {
+		return eveSolarSystems.ShipRangeNames, nil
+	}
+
+	var profiles []string
+	for _, name := range names {
+		profileName, found := eveSolarSystems.FindShipRange(name)
+		if !found {
+			return nil, fmt.Errorf("unknown range profile %q, use %s", name, strings.Join(eveSolarSystems.ShipRangeNames, ", "))
+		}
+		profiles = append(profiles, profileName)
+	}
+	return profiles, nil
+}
+
+func formatLightYears(lightYears float64) string {
+	return strconv.FormatFloat(lightYears, 'f', 2, 64)
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"github.com/sythe7448/Eve-Sonar/eveSolarSystems"
+	"io"
+	"strconv"
+	"strings"
+)
+
+func runCoverage(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("coverage", flag.ContinueOnError)
+	profile := flags.String("profile", "Capitals", "range profile to jump with")
+	list := flags.String("list", eveSolarSystems.DefaultStagingList, "staging list")
+	format := flags.String("format", formatText, "output format")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return errUsage
+	}
+
+	analysis, err := eveSolarSystems.AnalyzeCoverage(*list, *profile)
+	if err != nil {
+		return err
+	}
+
+	output := table{headers: []string{"Staging", "Systems", "Unique", "Regions"}}
+	for _, staging := range analysis.Stagings {
+		output.rows = append(output.rows, []string{
+			staging.System.Name,
+			strconv.Itoa(len(staging.Systems)),
+			strconv.Itoa(staging.Unique),
+			strings.Join(staging.Regions, ", "),
+		})
+	}
+	if err := writeOutput(stdout, *format, analysis, output); err != nil {
+		return err
+	}
+	if !strings.EqualFold(*format, formatText) {
+		return nil
+	}
+
+	fmt.Fprintf(stdout, "\n%s reaches %d systems with %s range, %d of them from more than one staging\n", analysis.List, analysis.Covered, analysis.Profile, analysis.Overlapping)
+	for _, overlap := range analysis.Overlaps {
+		fmt.Fprintf(stdout, "%s and %s overlap on %d systems\n", overlap.From, overlap.To, overlap.Systems)
+	}
+	for _, region := range analysis.Regions {
+		fmt.Fprintf(stdout, "%s: %d of %d systems\n", region.Region, region.Covered, region.Total)
+	}
+	if suggestion := analysis.Suggestion; suggestion != nil {
+		name := suggestion.System.Name
+		if suggestion.Region != "" {
+			name += " (" + suggestion.Region + ")"
+		}
+		fmt.Fprintf(stdout, "Staging in %s would reach %d more systems\n", name, suggestion.NewSystems)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"github.com/sythe7448/Eve-Sonar/eveSolarSystems"
+	"io"
+	"strconv"
+	"strings"
+)
+
+func runCynos(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("cynos", flag.ContinueOnError)
+	profile := flags.String("profile", "Capitals", "range profile to jump with")
+	friendly := flags.String("friendly", "", "only put cyno alts in the systems of this staging list")
+	format := flags.String("format", formatText, "output format")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) < 2 {
+		return errUsage
+	}
+
+	origin, err := findSystem(positional[0])
+	if err != nil {
+		return err
+	}
+	var destinations []eveSolarSystems.SolarSystem
+	for _, name := range positional[1:] {
+		destination, err := findSystem(name)
+		if err != nil {
+			return err
+		}
+		destinations = append(destinations, destination)
+	}
+	friendlySystems, err := findListSystems(*friendly)
+	if err != nil {
+		return err
+	}
+
+	plan, err := eveSolarSystems.PlanCynos(origin, destinations, *profile, friendlySystems)
+	if err != nil {
+		return err
+	}
+
+	output := table{headers: []string{"Destination", "Jumps", "LY", "Chain"}}
+	for _, chain := range plan.Chains {
+		systems := []string{plan.Origin.Name}
+		var lightYears float64
+		for _, step := range chain.Steps {
+			systems = append(systems, step.To.Name)
+			lightYears += step.LightYears
+		}
+		output.rows = append(output.rows, []string{
+			chain.Destination.Name,
+			strconv.Itoa(len(chain.Steps)),
+			formatLightYears(lightYears),
+			strings.Join(systems, " > "),
+		})
+	}
+	if err := writeOutput(stdout, *format, plan, output); err != nil {
+		return err
+	}
+	if !strings.EqualFold(*format, formatText) {
+		return nil
+	}
+
+	var midpoints []string
+	for _, midpoint := range plan.Midpoints {
+		midpoints = append(midpoints, midpoint.Name)
+	}
+	if len(midpoints) == 0 {
+		fmt.Fprintln(stdout, "\nNo cyno alts needed besides the destinations")
+	} else {
+		fmt.Fprintf(stdout, "\n%d cyno alts besides the destinations: %s\n", len(midpoints), strings.Join(midpoints, ", "))
+	}
+	for _, reason := range plan.Unreachable {
+		fmt.Fprintf(stdout, "Can't reach %s\n", reason)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"github.com/sythe7448/Eve-Sonar/eveSolarSystems"
+	"io"
+	"strconv"
+	"strings"
+)
+
+func runFuel(args []string, stdout io.Writer) error {
+	settings := eveSolarSystems.GetFuelSettings()
+	flags := flag.NewFlagSet("fuel", flag.ContinueOnError)
+	race := flags.String("race", settings.Race, "race of the ships flown, amarr, caldari, gallente or minmatar")
+	conservation := flags.Int("conservation", settings.JumpFuelConservation, "Jump Fuel Conservation level")
+	jumpFreighters := flags.Int("jump-freighters", settings.JumpFreighters, "Jump Freighters level")
+	hulls := flags.String("hull", "", "comma separated hulls flown, one per range profile, e.g. dreadnought,titan")
+	format := flags.String("format", formatText, "output format")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case len(positional) == 1 && positional[0] == "set":
+		settings.Race, settings.JumpFuelConservation, settings.JumpFreighters = *race, *conservation, *jumpFreighters
+		for _, name := range splitList(*hulls) {
+			hull, found := eveSolarSystems.FindJumpHull(name)
+			if !found {
+				return fmt.Errorf("unknown hull %q", name)
+			}
+			settings.Hulls[hull.Profile] = hull.Name
+		}
+		if err := eveSolarSystems.SaveFuelSettings(settings); err != nil {
+			return err
+		}
+		settings = eveSolarSystems.GetFuelSettings()
+	case len(positional) != 0:
+		return errUsage
+	}
+
+	output := table{headers: []string{"Profile", "Hull", "Isotope", "Base per LY", "Per LY"}}
+	for _, profile := range eveSolarSystems.ShipRangeNames {
+		hull := settings.Hull(profile)
+		fuel := eveSolarSystems.JumpFuel(hull, settings, 1)
+		output.rows = append(output.rows, []string{
+			profile,
+			hull.Name,
+			fuel.Isotope,
+			strconv.FormatFloat(hull.IsotopesPerLightYear, 'f', -1, 64),
+			strconv.Itoa(fuel.Isotopes),
+		})
+	}
+	if err := writeOutput(stdout, *format, settings, output); err != nil {
+		return err
+	}
+	if strings.EqualFold(*format, formatText) {
+		fmt.Fprintf(stdout, "Jump Fuel Conservation %d, Jump Freighters %d\n", settings.JumpFuelConservation, settings.JumpFreighters)
+	}
+	return nil
+}
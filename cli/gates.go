@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"github.com/sythe7448/Eve-Sonar/eveSolarSystems"
+	"io"
+	"os"
+	"strconv"
+)
+
+func runGates(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("gates", flag.ContinueOnError)
+	format := flags.String("format", formatText, "output format")
+	out := flags.String("out", eveSolarSystems.StargatesFile, "CSV file to save the stargates to")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 1 && positional[0] == "fetch" {
+		return fetchGates(*out, stdout)
+	}
+	if len(positional) != 3 {
+		return errUsage
+	}
+
+	from, err := findSystem(positional[1])
+	if err != nil {
+		return err
+	}
+
+	switch positional[0] {
+	case "route", "jumps":
+		to, err := findSystem(positional[2])
+		if err != nil {
+			return err
+		}
+		route, err := eveSolarSystems.FindGateRoute(from, to)
+		if err != nil {
+			return err
+		}
+		if positional[0] == "jumps" {
+			fmt.Fprintf(stdout, "%s to %s is %d gate jumps\n", from.Name, to.Name, len(route)-1)
+			return nil
+		}
+		output := table{headers: []string{"Jump", "System", "Sec"}}
+		for i, solarSystem := range route {
+			output.rows = append(output.rows, []string{
+				strconv.Itoa(i),
+				solarSystem.Name,
+				strconv.FormatFloat(eveSolarSystems.DisplaySecurity(solarSystem.Sec), 'f', 1, 64),
+			})
+		}
+		return writeOutput(stdout, *format, route, output)
+	case "within":
+		maxJumps, err := strconv.Atoi(positional[2])
+		if err != nil {
+			return fmt.Errorf("gate jumps %q isn't a number", positional[2])
+		}
+		systems, err := eveSolarSystems.SystemsWithinGates(from, maxJumps)
+		if err != nil {
+			return err
+		}
+		if systems == nil {
+			systems = []eveSolarSystems.GateDistance{}
+		}
+		output := table{headers: []string{"System", "Sec", "Region", "Gates"}}
+		for _, system := range systems {
+			output.rows = append(output.rows, []string{
+				system.System.Name,
+				strconv.FormatFloat(eveSolarSystems.DisplaySecurity(system.System.Sec), 'f', 1, 64),
+				eveSolarSystems.GetSystemRegion(system.System.ID),
+				strconv.Itoa(system.Jumps),
+			})
+		}
+		return writeOutput(stdout, *format, systems, output)
+	}
+
+	return errUsage
+}
+
+// fetchGates builds the stargate connections from ESI and saves them in the SDE layout, so the file can be
+// shipped and the next install doesn't have to fetch them.
+func fetchGates(out string, stdout io.Writer) error {
+	fmt.Fprintln(stdout, "Fetching every stargate from ESI, this takes a few minutes")
+	if err := eveSolarSystems.BuildStargateIndex(); err != nil {
+		return err
+	}
+	file, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	if err := eveSolarSystems.WriteStargatesCSV(file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Saved the stargates to %s\n", out)
+	return nil
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"github.com/sythe7448/Eve-Sonar/eveSolarSystems"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func runHistory(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("history", flag.ContinueOnError)
+	character := flags.String("character", "", "only this character's moves")
+	days := flags.Int("days", 0, "only the moves of the last days, defaults to everything kept")
+	out := flags.String("out", "", "CSV file to export to")
+	format := flags.String("format", formatText, "output format")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case len(positional) == 1 && (positional[0] == "on" || positional[0] == "off"):
+		if err := eveSolarSystems.SaveLocationHistoryEnabled(positional[0] == "on"); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Location history is %s\n", positional[0])
+		return nil
+	case len(positional) == 2 && positional[0] == "keep":
+		keepDays, err := strconv.Atoi(positional[1])
+		if err != nil {
+			return fmt.Errorf("days %q isn't a number", positional[1])
+		}
+		if err := eveSolarSystems.SaveLocationHistoryDays(keepDays); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Days of location history kept: %d\n", keepDays)
+		return nil
+	case len(positional) == 1 && positional[0] == "clear":
+		if err := eveSolarSystems.ClearLocationHistory(); err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, "Cleared location history")
+		return nil
+	case len(positional) != 0:
+		return errUsage
+	}
+
+	var since time.Time
+	if *days > 0 {
+		since = time.Now().AddDate(0, 0, -*days)
+	}
+	moves := eveSolarSystems.GetLocationHistory(*character, since)
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		if err := eveSolarSystems.WriteLocationHistoryCSV(file, moves); err != nil {
+			file.Close()
+			return err
+		}
+		if err := file.Close(); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Exported %d moves to %s\n", len(moves), *out)
+		return nil
+	}
+	if strings.EqualFold(*format, formatCSV) {
+		return eveSolarSystems.WriteLocationHistoryCSV(stdout, moves)
+	}
+
+	output := table{headers: []string{"Time", "Character", "System", "Sec", "Region", "From", "LY", "Via", "Fatigue", "Cooldown", "Source"}}
+	for _, move := range moves {
+		var lightYears, via, cooldown string
+		if move.From != "" {
+			lightYears = formatLightYears(move.LightYears)
+		}
+		switch {
+		case move.Gated:
+			via = "gate"
+		case move.Bridged:
+			via = "bridge"
+		case move.Jumped:
+			via = "jump"
+			cooldown = eveSolarSystems.FormatMinutes(move.CooldownMinutes)
+		}
+		output.rows = append(output.rows, []string{
+			move.Time.UTC().Format("2006-01-02 15:04:05"),
+			move.Character,
+			move.System.Name,
+			strconv.FormatFloat(eveSolarSystems.DisplaySecurity(move.System.Sec), 'f', 1, 64),
+			move.Region,
+			move.From,
+			lightYears,
+			via,
+			eveSolarSystems.FormatMinutes(move.FatigueMinutes),
+			cooldown,
+			move.Source,
+		})
+	}
+	if err := writeOutput(stdout, *format, moves, output); err != nil {
+		return err
+	}
+	if strings.EqualFold(*format, formatText) && !eveSolarSystems.IsLocationHistoryEnabled() {
+		fmt.Fprintln(stdout, "Location history is off, turn it on with history on")
+	}
+	return nil
+}
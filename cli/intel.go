@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"github.com/sythe7448/Eve-Sonar/eveSolarSystems"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func runIntel(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("intel", flag.ContinueOnError)
+	profile := flags.String("profile", "Capitals", "range profile reported systems alert within")
+	from := flags.String("from", "", "current system, defaults to only checking the stagings")
+	format := flags.String("format", formatText, "output format")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case len(positional) == 0:
+		fmt.Fprintf(stdout, "Intel channels: %s\n", strings.Join(eveSolarSystems.GetIntelChannels(), ", "))
+		if gateJumps := eveSolarSystems.GetIntelGateJumps(); gateJumps != eveSolarSystems.IntelGateJumpsOff {
+			fmt.Fprintf(stdout, "Alerting within %d gate jumps as well as jump range\n", gateJumps)
+			return nil
+		}
+		fmt.Fprintln(stdout, "Alerting within jump range only")
+		return nil
+	case len(positional) == 2 && positional[0] == "gates":
+		gateJumps := eveSolarSystems.IntelGateJumpsOff
+		if positional[1] != "off" {
+			if gateJumps, err = strconv.Atoi(positional[1]); err != nil || gateJumps < 0 {
+				return fmt.Errorf("gate jumps %q isn't a number or off", positional[1])
+			}
+		}
+		if err := eveSolarSystems.SaveIntelGateJumps(gateJumps); err != nil {
+			return err
+		}
+		if gateJumps == eveSolarSystems.IntelGateJumpsOff {
+			fmt.Fprintln(stdout, "Intel alerts only go by jump range")
+			return nil
+		}
+		fmt.Fprintf(stdout, "Intel alerts within %d gate jumps as well as jump range\n", gateJumps)
+		return nil
+	case len(positional) < 2 || positional[0] != "check":
+		return errUsage
+	}
+
+	profileName, found := eveSolarSystems.FindShipRange(*profile)
+	if !found {
+		return fmt.Errorf("unknown range profile %q", *profile)
+	}
+	var currentSolarSystemID string
+	if *from != "" {
+		current, err := findSystem(*from)
+		if err != nil {
+			return err
+		}
+		currentSolarSystemID = current.ID
+	}
+	message := strings.Join(positional[1:], " ")
+	report := eveSolarSystems.IntelReport{
+		Time:    time.Now(),
+		Message: message,
+		Systems: eveSolarSystems.MatchSystemsInText(message),
+	}
+	if len(report.Systems) == 0 {
+		return fmt.Errorf("no systems named in %q", message)
+	}
+
+	alerts := eveSolarSystems.CheckIntelReport(report, eveSolarSystems.ShipRanges[profileName], eveSolarSystems.GetIntelGateJumps(), currentSolarSystemID)
+	if alerts == nil {
+		alerts = []eveSolarSystems.IntelAlert{}
+	}
+	output := table{headers: []string{"System", "Near", "LY", "Gates"}}
+	for _, alert := range alerts {
+		output.rows = append(output.rows, []string{
+			alert.System.Name,
+			alert.Near,
+			formatLightYears(alert.LightYears),
+			eveSolarSystems.FormatGateJumps(alert.GateJumps),
+		})
+	}
+	return writeOutput(stdout, *format, alerts, output)
+}
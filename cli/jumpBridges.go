@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"github.com/sythe7448/Eve-Sonar/eveSolarSystems"
+	"io"
+	"os"
+)
+
+func runBridges(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("bridges", flag.ContinueOnError)
+	format := flags.String("format", formatText, "output format")
+	owner := flags.String("owner", "", "alliance or corporation that owns the bridges")
+	note := flags.String("note", "", "note for the bridge")
+	replace := flags.Bool("replace", false, "replace every saved bridge instead of merging")
+	out := flags.String("out", "", "file to export to, stdout when not given")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		return errUsage
+	}
+
+	switch positional[0] {
+	case "list":
+		if len(positional) != 1 {
+			return errUsage
+		}
+		bridges := eveSolarSystems.GetJumpBridges()
+		if bridges == nil {
+			bridges = []eveSolarSystems.JumpBridge{}
+		}
+		output := table{headers: []string{"From", "To", "LY", "Owner", "Note"}}
+		for _, bridge := range bridges {
+			from, _ := eveSolarSystems.FindSystemByName(bridge.From)
+			to, _ := eveSolarSystems.FindSystemByName(bridge.To)
+			output.rows = append(output.rows, []string{
+				bridge.From,
+				bridge.To,
+				formatLightYears(eveSolarSystems.ToLightYears(eveSolarSystems.Distance3D(from.Coordinates, to.Coordinates))),
+				bridge.Owner,
+				bridge.Note,
+			})
+		}
+		return writeOutput(stdout, *format, bridges, output)
+	case "add":
+		if len(positional) != 3 {
+			return errUsage
+		}
+		bridge, err := eveSolarSystems.AddJumpBridge(eveSolarSystems.JumpBridge{
+			From:  positional[1],
+			To:    positional[2],
+			Owner: *owner,
+			Note:  *note,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Added %s\n", bridge)
+		return nil
+	case "remove":
+		if len(positional) != 2 {
+			return errUsage
+		}
+		bridge, found, err := eveSolarSystems.RemoveJumpBridge(positional[1])
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("%s has no jump bridge", positional[1])
+		}
+		fmt.Fprintf(stdout, "Removed %s\n", bridge)
+		return nil
+	case "import":
+		if len(positional) != 2 {
+			return errUsage
+		}
+		var data []byte
+		if positional[1] == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(positional[1])
+		}
+		if err != nil {
+			return err
+		}
+		bridges, rejected := eveSolarSystems.ParseJumpBridges(string(data), *owner)
+		if err := eveSolarSystems.ImportJumpBridges(bridges, *replace); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Imported %d jump bridges\n", len(bridges))
+		printRejectedLines(stdout, rejected)
+		return nil
+	case "export":
+		if len(positional) != 1 {
+			return errUsage
+		}
+		text := eveSolarSystems.FormatJumpBridges(eveSolarSystems.GetJumpBridges())
+		if *out == "" {
+			_, err := io.WriteString(stdout, text)
+			return err
+		}
+		if err := os.WriteFile(*out, []byte(text), 0644); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Exported jump bridges to %s\n", *out)
+		return nil
+	}
+
+	return errUsage
+}
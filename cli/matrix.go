@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"github.com/sythe7448/Eve-Sonar/eveSolarSystems"
+	"io"
+	"os"
+	"strings"
+)
+
+func runMatrix(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("matrix", flag.ContinueOnError)
+	list := flags.String("list", eveSolarSystems.DefaultStagingList, "staging list")
+	out := flags.String("out", "", "CSV file to export to")
+	format := flags.String("format", formatText, "output format")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return errUsage
+	}
+
+	matrix, err := eveSolarSystems.GetStagingMatrix(*list)
+	if err != nil {
+		return err
+	}
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		if err := eveSolarSystems.WriteStagingMatrixCSV(file, matrix); err != nil {
+			file.Close()
+			return err
+		}
+		if err := file.Close(); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Exported the %s distances to %s\n", matrix.List, *out)
+		return nil
+	}
+	if strings.EqualFold(*format, formatCSV) {
+		return eveSolarSystems.WriteStagingMatrixCSV(stdout, matrix)
+	}
+
+	output := table{headers: []string{""}}
+	for i, solarSystem := range matrix.Systems {
+		output.headers = append(output.headers, solarSystem.Name)
+		row := []string{solarSystem.Name}
+		for j, distance := range matrix.Distances[i] {
+			if i == j {
+				row = append(row, "-")
+				continue
+			}
+			cell := formatLightYears(distance.LightYears)
+			if distance.GateJumps != eveSolarSystems.NoGateRoute {
+				cell += fmt.Sprintf(" (%d)", distance.GateJumps)
+			}
+			row = append(row, cell)
+		}
+		output.rows = append(output.rows, row)
+	}
+	if err := writeOutput(stdout, *format, matrix, output); err != nil {
+		return err
+	}
+	if !strings.EqualFold(*format, formatText) {
+		return nil
+	}
+
+	fmt.Fprintln(stdout, "\nLight years apart, gate jumps in brackets")
+	for _, profile := range eveSolarSystems.ShipRangeNames {
+		var pairs []string
+		for _, pair := range matrix.PairsWithin(profile) {
+			pairs = append(pairs, pair.From+" - "+pair.To)
+		}
+		if len(pairs) == 0 {
+			pairs = []string{"none"}
+		}
+		fmt.Fprintf(stdout, "Within %s range: %s\n", profile, strings.Join(pairs, ", "))
+	}
+	return nil
+}
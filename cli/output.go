@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Output formats every command supports
+const (
+	formatText = "text"
+	formatJSON = "json"
+	formatCSV  = "csv"
+)
+
+// table the rows a command prints for text and CSV output.
+type table struct {
+	headers []string
+	rows    [][]string
+}
+
+// writeOutput prints data as JSON, or the table as aligned text or CSV.
+func writeOutput(w io.Writer, format string, data any, output table) error {
+	switch strings.ToLower(format) {
+	case formatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(data)
+	case formatCSV:
+		csvWriter := csv.NewWriter(w)
+		if err := csvWriter.Write(output.headers); err != nil {
+			return err
+		}
+		if err := csvWriter.WriteAll(output.rows); err != nil {
+			return err
+		}
+		return csvWriter.Error()
+	case formatText:
+		tabWriter := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tabWriter, strings.Join(output.headers, "\t"))
+		for _, row := range output.rows {
+			fmt.Fprintln(tabWriter, strings.Join(row, "\t"))
+		}
+		return tabWriter.Flush()
+	}
+
+	return fmt.Errorf("unknown format %q, use text, json or csv", format)
+}
@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteOutput(t *testing.T) {
+	data := []map[string]any{{"system": "Bravo", "light_years": 3}}
+	output := table{
+		headers: []string{"System", "Note", "LY"},
+		rows:    [][]string{{"Bravo", "keepstar, pings", formatLightYears(3)}, {"Old Man Star", "", formatLightYears(12.345)}},
+	}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"text", "System        Note             LY\nBravo         keepstar, pings  3.00\nOld Man Star                   12.35\n"},
+		// Formats ignore case, CSV quotes what needs it
+		{"CSV", "System,Note,LY\nBravo,\"keepstar, pings\",3.00\nOld Man Star,,12.35\n"},
+		{"json", "[\n  {\n    \"light_years\": 3,\n    \"system\": \"Bravo\"\n  }\n]\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			var buffer bytes.Buffer
+			if err := writeOutput(&buffer, test.format, data, output); err != nil {
+				t.Fatal(err)
+			}
+			if buffer.String() != test.want {
+				t.Errorf("got\n%s\nwant\n%s", buffer.String(), test.want)
+			}
+		})
+	}
+
+	var buffer bytes.Buffer
+	if err := writeOutput(&buffer, "xml", data, output); err == nil || buffer.Len() > 0 {
+		t.Errorf("got %v and %q, want an unknown format error and nothing written", err, buffer.String())
+	}
+}
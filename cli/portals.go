@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"github.com/sythe7448/Eve-Sonar/eveSolarSystems"
+	"io"
+	"strconv"
+	"strings"
+)
+
+func runPortal(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("portal", flag.ContinueOnError)
+	portalTypeName := flags.String("type", "Titan", "titan or blops")
+	mass := flags.Float64("mass", 0, "mass of the fleet being bridged in million kg")
+	format := flags.String("format", formatText, "output format")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	portalType, found := eveSolarSystems.FindPortalType(*portalTypeName)
+	if !found {
+		return fmt.Errorf("unknown portal type %q, use titan or blops", *portalTypeName)
+	}
+
+	switch {
+	case len(positional) == 1 && positional[0] == "fuel":
+		fmt.Fprintf(stdout, "%s portals use %s isotopes per LY per million kg, they bridge %s\n",
+			portalType.Name, strconv.FormatFloat(eveSolarSystems.GetPortalFuelRate(portalType), 'f', -1, 64), strings.Join(portalType.Hulls, ", "))
+		return nil
+	case len(positional) == 2 && positional[0] == "fuel":
+		rate, err := strconv.ParseFloat(positional[1], 64)
+		if err != nil {
+			return fmt.Errorf("fuel rate %q isn't a number", positional[1])
+		}
+		if err := eveSolarSystems.SavePortalFuelRate(portalType, rate); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "%s portals now use %s isotopes per LY per million kg\n", portalType.Name, positional[1])
+		return nil
+	case len(positional) != 1:
+		return errUsage
+	}
+
+	from, err := findSystem(positional[0])
+	if err != nil {
+		return err
+	}
+	landings := eveSolarSystems.GetPortalLandings(from, portalType, *mass*1e6)
+
+	output := table{headers: []string{"System", "Sec", "Region", "List", "Owner", "Sov", "LY", "Isotopes"}}
+	for _, landing := range landings {
+		output.rows = append(output.rows, []string{
+			landing.System.Name,
+			strconv.FormatFloat(eveSolarSystems.DisplaySecurity(landing.System.Sec), 'f', 1, 64),
+			landing.Region,
+			landing.List,
+			landing.Owner,
+			landing.Sov,
+			formatLightYears(landing.LightYears),
+			strconv.Itoa(landing.Isotopes),
+		})
+	}
+	return writeOutput(stdout, *format, landings, output)
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"github.com/sythe7448/Eve-Sonar/eveSolarSystems"
+	"io"
+	"strconv"
+)
+
+func runReach(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("reach", flag.ContinueOnError)
+	profile := flags.String("profile", "Capitals", "range profile to jump with")
+	list := flags.String("list", "", "use every staging in a list as a target")
+	region := flags.String("region", "", "only systems in this region")
+	sov := flags.String("sov", "", "only systems whose sovereignty holder contains this")
+	format := flags.String("format", formatText, "output format")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	var targets []eveSolarSystems.SolarSystem
+	if *list != "" {
+		listName, found := eveSolarSystems.FindStagingList(*list)
+		if !found {
+			return fmt.Errorf("unknown staging list %q", *list)
+		}
+		for system := range eveSolarSystems.GetStagingList(listName) {
+			solarSystem, err := findSystem(system)
+			if err != nil {
+				return err
+			}
+			targets = append(targets, solarSystem)
+		}
+	}
+	for _, name := range positional {
+		solarSystem, err := findSystem(name)
+		if err != nil {
+			return err
+		}
+		targets = append(targets, solarSystem)
+	}
+	if len(targets) == 0 {
+		return errUsage
+	}
+	profileName, found := eveSolarSystems.FindShipRange(*profile)
+	if !found {
+		return fmt.Errorf("unknown range profile %q", *profile)
+	}
+
+	systems, err := eveSolarSystems.GetSystemsInReach(targets, eveSolarSystems.ShipRanges[profileName], eveSolarSystems.ReachFilter{
+		Region: *region,
+		Sov:    *sov,
+	})
+	if err != nil {
+		return err
+	}
+	if systems == nil {
+		systems = []eveSolarSystems.SystemInReach{}
+	}
+
+	output := table{headers: []string{"System", "Sec", "Region", "Sov", "LY"}}
+	for _, system := range systems {
+		output.rows = append(output.rows, []string{
+			system.System.Name,
+			strconv.FormatFloat(eveSolarSystems.DisplaySecurity(system.System.Sec), 'f', 1, 64),
+			system.Region,
+			system.Sov,
+			formatLightYears(system.LightYears),
+		})
+	}
+	return writeOutput(stdout, *format, systems, output)
+}
@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"github.com/sythe7448/Eve-Sonar/eveSolarSystems"
+	"io"
+)
+
+func runRegions(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("regions", flag.ContinueOnError)
+	format := flags.String("format", formatText, "output format")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case len(positional) == 1 && positional[0] == "fetch":
+		fmt.Fprintln(stdout, "Fetching every region from ESI")
+		if err := eveSolarSystems.BuildRegionIndex(); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Saved %d regions\n", len(eveSolarSystems.GetRegionNames()))
+		return nil
+	case len(positional) != 0:
+		return errUsage
+	}
+
+	if _, err := eveSolarSystems.EnsureRegionIndex(); err != nil {
+		return err
+	}
+	names := eveSolarSystems.GetRegionNames()
+	output := table{headers: []string{"Region"}}
+	for _, name := range names {
+		output.rows = append(output.rows, []string{name})
+	}
+	return writeOutput(stdout, *format, names, output)
+}
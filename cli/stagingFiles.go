@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/sythe7448/Eve-Sonar/eveSolarSystems"
+	"io"
+	"os"
+	"strings"
+)
+
+// exportStagings writes a staging list to a file or stdout, the format comes from the file name unless given.
+func exportStagings(list string, format string, out string, stdout io.Writer) error {
+	listName, found := eveSolarSystems.FindStagingList(list)
+	if !found {
+		return fmt.Errorf("unknown staging list %q", list)
+	}
+	format = stagingFileFormat(format, out)
+
+	data, err := eveSolarSystems.ExportStagingList(listName, format)
+	if err != nil {
+		return err
+	}
+	if out == "" {
+		_, err = stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(out, data, 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Exported %s to %s\n", listName, out)
+	return nil
+}
+
+// importStagings merges or replaces a staging list from a file, or stdin when the file is "-".
+func importStagings(list string, file string, format string, replace bool, stdout io.Writer) error {
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return err
+	}
+
+	entries, rejected, err := eveSolarSystems.ParseStagingFile(data, stagingFileFormat(format, file))
+	if err != nil {
+		return err
+	}
+	if list == "" {
+		list = eveSolarSystems.DefaultStagingList
+	}
+	result, err := eveSolarSystems.ImportStagings(list, entries, replace)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "Added %d, updated %d, unchanged %d, removed %d\n", len(result.Added), len(result.Updated), len(result.Unchanged), len(result.Removed))
+	for _, system := range result.Removed {
+		fmt.Fprintf(stdout, "Removed %s\n", system)
+	}
+	printRejectedLines(stdout, rejected)
+	return nil
+}
+
+// printRejectedLines one line per staging that couldn't be used.
+func printRejectedLines(stdout io.Writer, rejected []eveSolarSystems.RejectedLine) {
+	for _, line := range rejected {
+		fmt.Fprintf(stdout, "Rejected line %d %q: %s\n", line.Line, line.Text, line.Reason)
+	}
+}
+
+// stagingFileFormat the --format flag when it names a staging format, otherwise from the file extension.
+// The text output format means plain names.
+func stagingFileFormat(format string, file string) string {
+	format = strings.ToLower(format)
+	switch {
+	case format == formatText && file != "" && file != "-":
+		return eveSolarSystems.StagingFormatFromFilename(file)
+	case format == formatText:
+		return eveSolarSystems.StagingFormatNames
+	}
+	return format
+}
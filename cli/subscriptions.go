@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"github.com/sythe7448/Eve-Sonar/eveSolarSystems"
+	"io"
+	"strconv"
+)
+
+func runSubscriptions(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("subscriptions", flag.ContinueOnError)
+	format := flags.String("format", formatText, "output format")
+	sourceFormat := flags.String("type", "", "source format, json, csv, yaml or names, defaults to the source's extension")
+	every := flags.Int("every", eveSolarSystems.DefaultRefreshMinutes, "minutes between syncs")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		return errUsage
+	}
+
+	switch positional[0] {
+	case "list":
+		if len(positional) != 1 {
+			return errUsage
+		}
+		subscriptions := eveSolarSystems.GetSubscriptions()
+		if subscriptions == nil {
+			subscriptions = []eveSolarSystems.Subscription{}
+		}
+		output := table{headers: []string{"List", "Source", "Every", "Last sync", "Last error"}}
+		for _, subscription := range subscriptions {
+			output.rows = append(output.rows, []string{
+				subscription.List,
+				subscription.Source,
+				strconv.Itoa(subscription.RefreshMinutes) + "m",
+				subscription.LastSync.Format("2006-01-02 15:04"),
+				subscription.LastError,
+			})
+		}
+		return writeOutput(stdout, *format, subscriptions, output)
+	case "add":
+		if len(positional) != 3 {
+			return errUsage
+		}
+		subscription, change, err := eveSolarSystems.Subscribe(positional[1], positional[2], *sourceFormat, *every)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Subscribed %s to %s: %s\n", subscription.List, subscription.Source, change.Summary())
+		printRejectedLines(stdout, change.Rejected)
+		return nil
+	case "remove":
+		if len(positional) != 2 {
+			return errUsage
+		}
+		if err := eveSolarSystems.Unsubscribe(positional[1]); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Unsubscribed %s, its stagings can be edited again\n", positional[1])
+		return nil
+	case "sync":
+		if len(positional) != 2 {
+			return errUsage
+		}
+		change, err := eveSolarSystems.SyncSubscription(positional[1])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Synced %s: %s\n", positional[1], change.Summary())
+		printRejectedLines(stdout, change.Rejected)
+		return nil
+	case "log":
+		if len(positional) != 2 {
+			return errUsage
+		}
+		subscription := eveSolarSystems.GetSubscription(positional[1])
+		if subscription.List == "" {
+			return fmt.Errorf("staging list %q isn't subscribed", positional[1])
+		}
+		changes := subscription.Changes
+		if changes == nil {
+			changes = []eveSolarSystems.SubscriptionChange{}
+		}
+		output := table{headers: []string{"Time", "Changes"}}
+		for _, change := range changes {
+			output.rows = append(output.rows, []string{change.Time.Format("2006-01-02 15:04"), change.Summary()})
+		}
+		return writeOutput(stdout, *format, changes, output)
+	}
+
+	return errUsage
+}
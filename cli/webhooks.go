@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"github.com/sythe7448/Eve-Sonar/eveSolarSystems"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultReceiverAddress where the stand-in receiver listens, next to the local API
+const defaultReceiverAddress = "localhost:8082"
+
+func runWebhooks(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("webhooks", flag.ContinueOnError)
+	format := flags.String("format", formatText, "output format")
+	payloadType := flags.String("type", eveSolarSystems.WebhookDiscord, "payload format")
+	lists := flags.String("lists", "", "only fire for stagings in these lists, comma separated")
+	profiles := flags.String("profiles", "", "only fire for these range profiles, comma separated")
+	characters := flags.String("characters", "", "only fire for these characters, comma separated")
+	events := flags.String("events", "", "only fire on these events, comma separated, defaults to entered and left range")
+	address := flags.String("addr", defaultReceiverAddress, "address the stand-in receiver listens on")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		return errUsage
+	}
+
+	switch positional[0] {
+	case "list":
+		if len(positional) != 1 {
+			return errUsage
+		}
+		webhooks := eveSolarSystems.GetWebhooks()
+		if webhooks == nil {
+			webhooks = []eveSolarSystems.Webhook{}
+		}
+		output := table{headers: []string{"Name", "Type", "URL", "Events", "Lists", "Profiles", "Characters"}}
+		for _, webhook := range webhooks {
+			output.rows = append(output.rows, []string{
+				webhook.Name,
+				webhook.Format,
+				webhook.URL,
+				strings.Join(webhook.Events, ","),
+				strings.Join(webhook.Lists, ","),
+				strings.Join(webhook.Profiles, ","),
+				strings.Join(webhook.Characters, ","),
+			})
+		}
+		return writeOutput(stdout, *format, webhooks, output)
+	case "add":
+		if len(positional) != 3 {
+			return errUsage
+		}
+		webhook, err := eveSolarSystems.SaveWebhook(eveSolarSystems.Webhook{
+			Name:       positional[1],
+			URL:        positional[2],
+			Format:     *payloadType,
+			Events:     splitList(*events),
+			Lists:      splitList(*lists),
+			Profiles:   splitList(*profiles),
+			Characters: splitList(*characters),
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Saved webhook %s\n", webhook.Name)
+		return nil
+	case "remove":
+		if len(positional) != 2 {
+			return errUsage
+		}
+		if err := eveSolarSystems.RemoveWebhook(positional[1]); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Removed webhook %s\n", positional[1])
+		return nil
+	case "test":
+		if len(positional) != 2 {
+			return errUsage
+		}
+		webhook, found := eveSolarSystems.FindWebhook(positional[1])
+		if !found {
+			return fmt.Errorf("unknown webhook %q", positional[1])
+		}
+		if err := eveSolarSystems.TestWebhook(webhook); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Test sent to %s\n", webhook.Name)
+		return nil
+	case "receive":
+		if len(positional) != 1 {
+			return errUsage
+		}
+		return runWebhookReceiver(*address, stdout)
+	}
+
+	return errUsage
+}
+
+// runWebhookReceiver a stand-in for Discord or Slack that prints every webhook it receives, for trying webhooks locally.
+func runWebhookReceiver(address string, stdout io.Writer) error {
+	fmt.Fprintf(stdout, "Receiving webhooks on http://%s, add one with: Eve-Sonar webhooks add local http://%s --type json\n", address, address)
+	return http.ListenAndServe(address, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintf(stdout, "%s %s %s\n%s\n", time.Now().Format("15:04:05"), r.Method, r.URL.Path, body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+}
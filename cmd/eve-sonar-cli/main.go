@@ -0,0 +1,11 @@
+// Command eve-sonar-cli runs the Eve-Sonar commands without the app, so it builds without fyne or a C compiler.
+package main
+
+import (
+	"github.com/sythe7448/Eve-Sonar/cli"
+	"os"
+)
+
+func main() {
+	os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr))
+}
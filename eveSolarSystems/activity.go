@@ -0,0 +1,130 @@
+package eveSolarSystems
+
+import (
+	"github.com/sythe7448/Eve-Sonar/api"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// SystemActivity kills and jumps in a system over the last hour as reported by ESI.
+type SystemActivity struct {
+	ShipKills int `json:"ship_kills"`
+	PodKills  int `json:"pod_kills"`
+	NpcKills  int `json:"npc_kills"`
+	Jumps     int `json:"jumps"`
+}
+
+// ActivityFilter how in range stagings are sorted and which are hidden for being too quiet.
+type ActivityFilter struct {
+	SortBy   string
+	MinKills int
+	MinJumps int
+}
+
+// Sort options for ActivityFilter.SortBy
+const (
+	SortByName      = "Name"
+	SortByShipKills = "Ship Kills"
+	SortByPodKills  = "Pod Kills"
+	SortByNpcKills  = "NPC Kills"
+	SortByJumps     = "Jumps"
+)
+
+var ActivitySortOptions = []string{SortByName, SortByShipKills, SortByPodKills, SortByNpcKills, SortByJumps}
+
+// kills and jumps only live an hour on ESI so they are kept in memory instead of bolt
+var systemKills = make(map[string]api.SystemKills)
+var systemJumps = make(map[string]int)
+var activityLock sync.RWMutex
+
+// RefreshSystemKills replaces the cached kill stats, returns when ESI will have new data.
+func RefreshSystemKills() (time.Time, error) {
+	kills, expires, err := api.GetSystemKills()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	killsByID := make(map[string]api.SystemKills)
+	for _, k := range kills {
+		killsByID[strconv.Itoa(k.SystemID)] = k
+	}
+
+	activityLock.Lock()
+	systemKills = killsByID
+	activityLock.Unlock()
+
+	return expires, nil
+}
+
+// RefreshSystemJumps replaces the cached jump stats, returns when ESI will have new data.
+func RefreshSystemJumps() (time.Time, error) {
+	jumps, expires, err := api.GetSystemJumps()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	jumpsByID := make(map[string]int)
+	for _, j := range jumps {
+		jumpsByID[strconv.Itoa(j.SystemID)] = j.ShipJumps
+	}
+
+	activityLock.Lock()
+	systemJumps = jumpsByID
+	activityLock.Unlock()
+
+	return expires, nil
+}
+
+// GetSystemActivity returns the cached activity of a system, zero if ESI reported nothing.
+func GetSystemActivity(systemID string) SystemActivity {
+	activityLock.RLock()
+	defer activityLock.RUnlock()
+
+	kills := systemKills[systemID]
+	return SystemActivity{
+		ShipKills: kills.ShipKills,
+		PodKills:  kills.PodKills,
+		NpcKills:  kills.NpcKills,
+		Jumps:     systemJumps[systemID],
+	}
+}
+
+// FilterAndSortStagings drops stagings under the activity thresholds and orders the rest.
+// Activity is sorted busiest first, names alphabetically.
+func FilterAndSortStagings(stagings []StagingInRange, filter ActivityFilter) []StagingInRange {
+	var filtered []StagingInRange
+	for _, staging := range stagings {
+		if staging.Activity.ShipKills+staging.Activity.PodKills < filter.MinKills {
+			continue
+		}
+		if staging.Activity.Jumps < filter.MinJumps {
+			continue
+		}
+		filtered = append(filtered, staging)
+	}
+
+	activityValue := func(activity SystemActivity) int {
+		switch filter.SortBy {
+		case SortByShipKills:
+			return activity.ShipKills
+		case SortByPodKills:
+			return activity.PodKills
+		case SortByNpcKills:
+			return activity.NpcKills
+		case SortByJumps:
+			return activity.Jumps
+		}
+		return 0
+	}
+	sort.SliceStable(filtered, func(i, j int) bool {
+		a, b := activityValue(filtered[i].Activity), activityValue(filtered[j].Activity)
+		if a != b {
+			return a > b
+		}
+		return filtered[i].System.Name < filtered[j].System.Name
+	})
+
+	return filtered
+}
@@ -0,0 +1,98 @@
+package eveSolarSystems
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+const (
+	desktopAlertsSetting string = "desktopAlerts"
+	alertSoundSetting    string = "alertSound"
+	mutedListsSetting    string = "mutedLists"
+)
+
+// AreDesktopAlertsEnabled true unless desktop notifications for range changes were turned off.
+func AreDesktopAlertsEnabled() bool {
+	return GetSetting(desktopAlertsSetting) != "false"
+}
+
+// SaveDesktopAlertsEnabled turns desktop notifications for range changes on or off.
+func SaveDesktopAlertsEnabled(enabled bool) error {
+	return SaveSetting(desktopAlertsSetting, fmt.Sprint(enabled))
+}
+
+// GetAlertSound the sound file played on range changes, empty for no sound.
+func GetAlertSound() string {
+	return GetSetting(alertSoundSetting)
+}
+
+// SaveAlertSound saves the sound file played on range changes, empty turns the sound off.
+func SaveAlertSound(path string) error {
+	path = strings.TrimSpace(path)
+	if path != "" {
+		if _, err := os.Stat(path); err != nil {
+			return err
+		}
+	}
+	return SaveSetting(alertSoundSetting, path)
+}
+
+// IsListMuted true when range changes for stagings in a list shouldn't raise alerts.
+func IsListMuted(list string) bool {
+	return containsString(GetMutedLists(), list)
+}
+
+// GetMutedLists the staging lists that don't raise alerts.
+func GetMutedLists() []string {
+	return splitCommaList(GetSetting(mutedListsSetting))
+}
+
+// SetListMuted mutes or unmutes alerts for a staging list.
+func SetListMuted(list string, muted bool) error {
+	var lists []string
+	for _, mutedList := range GetMutedLists() {
+		if mutedList != list {
+			lists = append(lists, mutedList)
+		}
+	}
+	if muted {
+		lists = append(lists, list)
+	}
+	return SaveSetting(mutedListsSetting, strings.Join(lists, ","))
+}
+
+// PlayAlertSound plays a sound file with the player that comes with the OS, returning once it has finished.
+func PlayAlertSound(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		// SoundPlayer only plays wav files
+		cmd = exec.Command("powershell", "-NoProfile", "-Command", "(New-Object Media.SoundPlayer $args[0]).PlaySync()", path)
+	case "darwin":
+		cmd = exec.Command("afplay", path)
+	default:
+		player, err := findSoundPlayer()
+		if err != nil {
+			return err
+		}
+		cmd = exec.Command(player, path)
+	}
+	return cmd.Run()
+}
+
+// findSoundPlayer the first sound player installed on Linux, PulseAudio then ALSA.
+func findSoundPlayer() (string, error) {
+	for _, player := range []string{"paplay", "pw-play", "aplay"} {
+		if path, err := exec.LookPath(player); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no sound player found, install paplay or aplay")
+}
@@ -0,0 +1,334 @@
+package eveSolarSystems
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf16"
+)
+
+// ChatLogLine a single message read from an EVE chat log.
+type ChatLogLine struct {
+	Channel  string
+	Listener string
+	Time     time.Time
+	Sender   string
+	Message  string
+}
+
+const (
+	chatLogsPathSetting string = "chatLogsPath"
+	// chatLogPollInterval how often the chat logs are checked for new lines
+	chatLogPollInterval = time.Second * 2
+	// chatLogMaxAge logs not written to within this are from old sessions and skipped
+	chatLogMaxAge = time.Hour * 24
+	// chatLogHeaderSize is enough bytes to cover the header block at the start of every log
+	chatLogHeaderSize = 4096
+)
+
+var (
+	// Local_20230801_120000_91234567.txt, older clients leave off the character id
+	chatLogFileRegex = regexp.MustCompile(`^(.+)_\d{8}_\d{6}(?:_\d+)?\.txt$`)
+	chatLineRegex    = regexp.MustCompile(`^\[ (\d{4}\.\d{2}\.\d{2} \d{2}:\d{2}:\d{2}) \] (.+?) > (.*)$`)
+	listenerRegex    = regexp.MustCompile(`Listener:\s+(.+)`)
+	localChangeRegex = regexp.MustCompile(`Channel changed to Local : (.+)`)
+)
+
+// GetChatLogsPath returns the saved Chatlogs directory or the EVE client default.
+func GetChatLogsPath() string {
+	if path := GetSetting(chatLogsPathSetting); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, "Documents", "EVE", "logs", "Chatlogs")
+}
+
+// SaveChatLogsPath saves the Chatlogs directory to use.
+func SaveChatLogsPath(path string) error {
+	return SaveSetting(chatLogsPathSetting, path)
+}
+
+// chatLogFile where a chat log was last read up to.
+type chatLogFile struct {
+	listener string
+	offset   int64
+	partial  string
+}
+
+// chatLogTailer follows every chat log in a directory for the matching channels and returns only new lines.
+type chatLogTailer struct {
+	dir       string
+	channel   func(name string) bool
+	fromStart bool
+	files     map[string]*chatLogFile
+}
+
+// newChatLogTailer creates a tailer for the channels matched by channel.
+// fromStart reads logs already on disk in full, otherwise only lines written after the tailer first sees a log are returned.
+func newChatLogTailer(dir string, channel func(name string) bool, fromStart bool) *chatLogTailer {
+	return &chatLogTailer{
+		dir:       dir,
+		channel:   channel,
+		fromStart: fromStart,
+		files:     make(map[string]*chatLogFile),
+	}
+}
+
+// poll reads whatever was written to the matching logs since the last poll, oldest log first.
+func (t *chatLogTailer) poll() ([]ChatLogLine, error) {
+	entries, err := os.ReadDir(t.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []ChatLogLine
+	// File names start with the channel and then the date so sorted order keeps sessions in order
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		matches := chatLogFileRegex.FindStringSubmatch(entry.Name())
+		if matches == nil || !t.channel(matches[1]) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) > chatLogMaxAge {
+			continue
+		}
+
+		path := filepath.Join(t.dir, entry.Name())
+		file, seen := t.files[path]
+		if !seen {
+			file = &chatLogFile{listener: readChatLogListener(path)}
+			if !t.fromStart {
+				file.offset = info.Size() - info.Size()%2
+			}
+			t.files[path] = file
+		}
+		if info.Size() <= file.offset {
+			continue
+		}
+
+		newLines, err := file.read(path)
+		if err != nil {
+			continue
+		}
+		for _, text := range newLines {
+			if line, ok := parseChatLine(text); ok {
+				line.Channel = matches[1]
+				line.Listener = file.listener
+				lines = append(lines, line)
+			}
+		}
+	}
+
+	return lines, nil
+}
+
+// read returns the complete lines written since the last read, keeping any half written line for next time.
+func (f *chatLogFile) read(path string) ([]string, error) {
+	logFile, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer logFile.Close()
+
+	if _, err := logFile.Seek(f.offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(logFile)
+	if err != nil {
+		return nil, err
+	}
+	// Stay on a UTF-16 code unit boundary
+	data = data[:len(data)-len(data)%2]
+	f.offset += int64(len(data))
+
+	text := f.partial + decodeUTF16(data)
+	lines := strings.Split(text, "\n")
+	f.partial = lines[len(lines)-1]
+
+	return lines[:len(lines)-1], nil
+}
+
+// readChatLogListener pulls the listening character's name from the log header.
+func readChatLogListener(path string) string {
+	logFile, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer logFile.Close()
+
+	header := make([]byte, chatLogHeaderSize)
+	n, _ := io.ReadFull(logFile, header)
+	matches := listenerRegex.FindStringSubmatch(decodeUTF16(header[:n-n%2]))
+	if matches == nil {
+		return ""
+	}
+	return strings.TrimSpace(matches[1])
+}
+
+// parseChatLine splits a "[ 2023.08.01 12:00:00 ] Sender > message" line, header lines are not messages.
+func parseChatLine(text string) (ChatLogLine, bool) {
+	text = strings.TrimSpace(strings.TrimPrefix(text, "\ufeff"))
+	matches := chatLineRegex.FindStringSubmatch(text)
+	if matches == nil {
+		return ChatLogLine{}, false
+	}
+	// EVE writes chat times in UTC
+	lineTime, err := time.Parse("2006.01.02 15:04:05", matches[1])
+	if err != nil {
+		return ChatLogLine{}, false
+	}
+
+	return ChatLogLine{
+		Time:    lineTime,
+		Sender:  strings.TrimSpace(matches[2]),
+		Message: strings.TrimSpace(matches[3]),
+	}, true
+}
+
+// decodeUTF16 the client writes logs as little endian UTF-16.
+func decodeUTF16(data []byte) string {
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = uint16(data[2*i]) | uint16(data[2*i+1])<<8
+	}
+	return strings.ReplaceAll(string(utf16.Decode(units)), "\r", "")
+}
+
+// ChatLogWatcher a LocationSource that follows Local chat logs and reports when a listener character changes system.
+type ChatLogWatcher struct {
+	dir       string
+	locations map[string]string
+	stop      chan struct{}
+	lock      sync.Mutex
+}
+
+// NewChatLogWatcher creates a watcher for the Chatlogs directory dir.
+func NewChatLogWatcher(dir string) *ChatLogWatcher {
+	return &ChatLogWatcher{
+		dir:       dir,
+		locations: make(map[string]string),
+	}
+}
+
+func (w *ChatLogWatcher) Name() string {
+	return SourceChatLog
+}
+
+// Start tails the Local logs in the background until Stop is called.
+func (w *ChatLogWatcher) Start(events chan<- LocationEvent) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.stop != nil {
+		return
+	}
+	w.stop = make(chan struct{})
+
+	go func(stop chan struct{}) {
+		// Read existing logs in full so the current system is known straight away
+		tailer := newChatLogTailer(w.dir, func(name string) bool { return name == "Local" }, true)
+		lines, _ := tailer.poll()
+		arrived := make(map[string]time.Time)
+		for _, line := range lines {
+			if w.handleLine(line) {
+				arrived[line.Listener] = line.Time
+			}
+		}
+		// Only the last system of each listener matters from the old lines, sent in the order they arrived so
+		// whoever moved last ends up as the current system when following any character
+		locations := w.Locations()
+		listeners := make([]string, 0, len(locations))
+		for listener := range locations {
+			listeners = append(listeners, listener)
+		}
+		sort.Slice(listeners, func(i, j int) bool {
+			if !arrived[listeners[i]].Equal(arrived[listeners[j]]) {
+				return arrived[listeners[i]].Before(arrived[listeners[j]])
+			}
+			return listeners[i] < listeners[j]
+		})
+		for _, listener := range listeners {
+			events <- LocationEvent{
+				Character:     listener,
+				SolarSystemID: locations[listener],
+				Time:          arrived[listener],
+				Source:        SourceChatLog,
+			}
+		}
+
+		ticker := time.NewTicker(chatLogPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+			lines, _ := tailer.poll()
+			for _, line := range lines {
+				if w.handleLine(line) {
+					events <- LocationEvent{
+						Character:     line.Listener,
+						SolarSystemID: w.Locations()[line.Listener],
+						Time:          line.Time,
+						Source:        SourceChatLog,
+					}
+				}
+			}
+		}
+	}(w.stop)
+}
+
+// Stop stops tailing the logs.
+func (w *ChatLogWatcher) Stop() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.stop != nil {
+		close(w.stop)
+		w.stop = nil
+	}
+}
+
+// Locations returns the last known system ID of every listener seen in the logs.
+func (w *ChatLogWatcher) Locations() map[string]string {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	locations := make(map[string]string)
+	for listener, solarSystemID := range w.locations {
+		locations[listener] = solarSystemID
+	}
+	return locations
+}
+
+// handleLine records a Local system change, returns true when the listener moved.
+func (w *ChatLogWatcher) handleLine(line ChatLogLine) bool {
+	if line.Sender != "EVE System" {
+		return false
+	}
+	matches := localChangeRegex.FindStringSubmatch(line.Message)
+	if matches == nil {
+		return false
+	}
+	solarSystem, found := FindSystemByName(matches[1])
+	if !found {
+		return false
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	changed := w.locations[line.Listener] != solarSystem.ID
+	w.locations[line.Listener] = solarSystem.ID
+
+	return changed
+}
@@ -0,0 +1,62 @@
+package eveSolarSystems
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+// writeTestChatLog writes a Local log the way the client does, as little endian UTF-16 with a header.
+func writeTestChatLog(t *testing.T, path string, listener string, lines ...string) {
+	t.Helper()
+	text := "\ufeff\r\n  Channel Name:    Local\r\n  Listener:        " + listener + "\r\n\r\n" + strings.Join(lines, "\r\n") + "\r\n"
+	var data []byte
+	for _, unit := range utf16.Encode([]rune(text)) {
+		data = append(data, byte(unit), byte(unit>>8))
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestChatLogWatcherReplayOrder(t *testing.T) {
+	dir := t.TempDir()
+	writeTestChatLog(t, filepath.Join(dir, "Local_20261019_100000_1.txt"), "Zed",
+		"[ 2026.10.19 10:05:00 ] EVE System > Channel changed to Local : Zulu")
+	writeTestChatLog(t, filepath.Join(dir, "Local_20261019_100000_2.txt"), "Amy",
+		"[ 2026.10.19 10:00:00 ] EVE System > Channel changed to Local : Nova",
+		"[ 2026.10.19 10:20:00 ] EVE System > Channel changed to Local : Alpha",
+		"[ 2026.10.19 10:21:00 ] Amy > o7")
+	writeTestChatLog(t, filepath.Join(dir, "Local_20261019_100000_3.txt"), "Bob",
+		"[ 2026.10.19 10:10:00 ] EVE System > Channel changed to Local : Nova",
+		"[ 2026.10.19 10:30:00 ] EVE System > Channel changed to Local : Nova")
+
+	watcher := NewChatLogWatcher(dir)
+	events := make(chan LocationEvent, 3)
+	watcher.Start(events)
+	defer watcher.Stop()
+
+	// Whoever arrived last comes last, staying in the same system isn't an arrival
+	want := []struct {
+		character, system string
+		arrived           time.Time
+	}{
+		{"Zed", "Zulu", time.Date(2026, 10, 19, 10, 5, 0, 0, time.UTC)},
+		{"Bob", "Nova", time.Date(2026, 10, 19, 10, 10, 0, 0, time.UTC)},
+		{"Amy", "Alpha", time.Date(2026, 10, 19, 10, 20, 0, 0, time.UTC)},
+	}
+	for _, expected := range want {
+		select {
+		case event := <-events:
+			if event.Character != expected.character || event.SolarSystemID != findTestSystem(t, expected.system).ID || !event.Time.Equal(expected.arrived) {
+				t.Errorf("got %s in %s at %s, want %s in %s at %s", event.Character, event.SolarSystemID, event.Time,
+					expected.character, expected.system, expected.arrived)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the replayed locations")
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package eveSolarSystems
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// StagingCoverage the systems one staging can jump to.
+type StagingCoverage struct {
+	System  SolarSystem `json:"system"`
+	Systems []string    `json:"systems"`
+	// Unique how many of the systems no other staging in the list reaches
+	Unique  int      `json:"unique"`
+	Regions []string `json:"regions"`
+}
+
+// StagingOverlap how many systems two stagings both reach.
+type StagingOverlap struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Systems int    `json:"systems"`
+}
+
+// RegionCoverage how much of a region the list reaches, counting only systems that can be jumped to.
+type RegionCoverage struct {
+	Region  string `json:"region"`
+	Covered int    `json:"covered"`
+	Total   int    `json:"total"`
+}
+
+// CoverageSuggestion the staging that would reach the most systems the list doesn't already.
+type CoverageSuggestion struct {
+	System     SolarSystem `json:"system"`
+	Region     string      `json:"region"`
+	NewSystems int         `json:"new_systems"`
+}
+
+// CoverageAnalysis what a staging list reaches with a range profile.
+type CoverageAnalysis struct {
+	List     string            `json:"list"`
+	Profile  string            `json:"profile"`
+	Stagings []StagingCoverage `json:"stagings"`
+	// Covered systems at least one staging reaches, Overlapping those more than one does
+	Covered     int                 `json:"covered"`
+	Overlapping int                 `json:"overlapping"`
+	Overlaps    []StagingOverlap    `json:"overlaps"`
+	Regions     []RegionCoverage    `json:"regions"`
+	Suggestion  *CoverageSuggestion `json:"suggestion,omitempty"`
+}
+
+// AnalyzeCoverage the systems each staging of a list can jump to, where they overlap, and the one system that would
+// add the most new coverage as another staging. Highsec and systems outside New Eden can't be jumped to so aren't counted.
+func AnalyzeCoverage(list string, profile string) (CoverageAnalysis, error) {
+	listName, found := FindStagingList(list)
+	if !found {
+		return CoverageAnalysis{}, fmt.Errorf("unknown staging list %q", list)
+	}
+	profileName, found := FindShipRange(profile)
+	if !found {
+		return CoverageAnalysis{}, fmt.Errorf("unknown range profile %q", profile)
+	}
+	jumpRange := ShipRanges[profileName]
+	// Without regions the analysis still counts systems, it just has no region breakdown
+	regions, err := EnsureRegionIndex()
+	if err != nil {
+		log.Println("Error building region index:", err)
+	}
+
+	var destinations []SolarSystem
+	for _, solarSystem := range GetAllSolarSystems() {
+		if strings.HasPrefix(solarSystem.ID, newEdenIDPrefix) && !IsHighsec(solarSystem) {
+			destinations = append(destinations, solarSystem)
+		}
+	}
+
+	analysis := CoverageAnalysis{List: listName, Profile: profileName, Overlaps: []StagingOverlap{}, Regions: []RegionCoverage{}}
+	var stagings []SolarSystem
+	for system := range GetStagingList(listName) {
+		if solarSystem, found := FindSystemByName(system); found {
+			stagings = append(stagings, solarSystem)
+		}
+	}
+	sort.Slice(stagings, func(i, j int) bool {
+		return stagings[i].Name < stagings[j].Name
+	})
+
+	// reachedBy the index of each staging that reaches a system, by system ID
+	reachedBy := make(map[string][]int)
+	for i, staging := range stagings {
+		coverage := StagingCoverage{System: staging, Systems: []string{}, Regions: []string{}}
+		for _, solarSystem := range withinRange(staging, destinations, jumpRange) {
+			coverage.Systems = append(coverage.Systems, solarSystem.Name)
+			reachedBy[solarSystem.ID] = append(reachedBy[solarSystem.ID], i)
+			if region := regions[solarSystem.ID]; region != "" && !containsString(coverage.Regions, region) {
+				coverage.Regions = append(coverage.Regions, region)
+			}
+		}
+		sort.Strings(coverage.Systems)
+		sort.Strings(coverage.Regions)
+		analysis.Stagings = append(analysis.Stagings, coverage)
+	}
+
+	overlaps := make(map[[2]int]int)
+	for _, indexes := range reachedBy {
+		analysis.Covered++
+		if len(indexes) == 1 {
+			analysis.Stagings[indexes[0]].Unique++
+			continue
+		}
+		analysis.Overlapping++
+		for i := range indexes {
+			for _, other := range indexes[i+1:] {
+				overlaps[[2]int{indexes[i], other}]++
+			}
+		}
+	}
+	for pair, systems := range overlaps {
+		analysis.Overlaps = append(analysis.Overlaps, StagingOverlap{From: stagings[pair[0]].Name, To: stagings[pair[1]].Name, Systems: systems})
+	}
+	sort.Slice(analysis.Overlaps, func(i, j int) bool {
+		if analysis.Overlaps[i].Systems != analysis.Overlaps[j].Systems {
+			return analysis.Overlaps[i].Systems > analysis.Overlaps[j].Systems
+		}
+		return analysis.Overlaps[i].From+analysis.Overlaps[i].To < analysis.Overlaps[j].From+analysis.Overlaps[j].To
+	})
+
+	regionCoverage := make(map[string]*RegionCoverage)
+	for _, solarSystem := range destinations {
+		region := regions[solarSystem.ID]
+		if region == "" {
+			continue
+		}
+		if regionCoverage[region] == nil {
+			regionCoverage[region] = &RegionCoverage{Region: region}
+		}
+		regionCoverage[region].Total++
+		if _, covered := reachedBy[solarSystem.ID]; covered {
+			regionCoverage[region].Covered++
+		}
+	}
+	for _, coverage := range regionCoverage {
+		if coverage.Covered > 0 {
+			analysis.Regions = append(analysis.Regions, *coverage)
+		}
+	}
+	sort.Slice(analysis.Regions, func(i, j int) bool {
+		return analysis.Regions[i].Region < analysis.Regions[j].Region
+	})
+
+	analysis.Suggestion = suggestStaging(destinations, reachedBy, jumpRange, regions)
+	return analysis, nil
+}
+
+// suggestStaging the system that reaches the most systems nothing reaches yet, nil when every system is reached.
+func suggestStaging(destinations []SolarSystem, reachedBy map[string][]int, jumpRange float64, regions map[string]string) *CoverageSuggestion {
+	var uncovered []SolarSystem
+	for _, solarSystem := range destinations {
+		if _, covered := reachedBy[solarSystem.ID]; !covered {
+			uncovered = append(uncovered, solarSystem)
+		}
+	}
+
+	var best *CoverageSuggestion
+	for _, candidate := range destinations {
+		newSystems := len(withinRange(candidate, uncovered, jumpRange))
+		if best == nil || newSystems > best.NewSystems {
+			best = &CoverageSuggestion{System: candidate, Region: regions[candidate.ID], NewSystems: newSystems}
+		}
+	}
+	if best == nil || best.NewSystems == 0 {
+		return nil
+	}
+	return best
+}
@@ -0,0 +1,377 @@
+package eveSolarSystems
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"math/bits"
+	"sort"
+	"strings"
+)
+
+// CynoChain the jumps from the origin to one destination, every system after the origin needs a cyno.
+type CynoChain struct {
+	Destination SolarSystem `json:"destination"`
+	Steps       []RouteStep `json:"steps"`
+}
+
+// CynoPlan where to put cyno alts so every destination can be jumped to from the origin.
+type CynoPlan struct {
+	Origin  SolarSystem `json:"origin"`
+	Profile string      `json:"profile"`
+	// Midpoints the systems that need a cyno alt on top of the destinations themselves
+	Midpoints   []SolarSystem `json:"midpoints"`
+	Chains      []CynoChain   `json:"chains"`
+	Unreachable []string      `json:"unreachable"`
+}
+
+// midpointCost the cost of a new midpoint against one more jump, so fewer cyno alts always wins over fewer jumps
+const midpointCost = 1000
+
+// maxExactCynoDestinations the most destinations the fewest midpoints are searched for exactly, the search grows
+// threefold with each one so more than this tries several orders of planning one destination at a time instead.
+const maxExactCynoDestinations = 6
+
+// cynoFallbackOrders how many destination orders are tried when there are too many destinations to search exactly.
+const cynoFallbackOrders = 6
+
+// PlanCynos midpoint systems that get a fleet from the origin to every destination. Up to maxExactCynoDestinations
+// destinations it finds the fewest midpoints possible, then the fewest jumps. With more it plans one destination at
+// a time onto the cynos already planned, for the furthest first, the nearest first and a few other orders, keeping
+// the best, which usually shares well but isn't guaranteed to be the fewest. Midpoints are lowsec or nullsec, and
+// only the friendly systems when any are given, e.g. systems with your structures.
+func PlanCynos(origin SolarSystem, destinations []SolarSystem, profile string, friendly []SolarSystem) (CynoPlan, error) {
+	profileName, found := FindShipRange(profile)
+	if !found {
+		return CynoPlan{}, fmt.Errorf("unknown range profile %q", profile)
+	}
+	if len(destinations) == 0 {
+		return CynoPlan{}, fmt.Errorf("no destinations")
+	}
+	plan := CynoPlan{Origin: origin, Profile: profileName, Midpoints: []SolarSystem{}, Chains: []CynoChain{}, Unreachable: []string{}}
+
+	candidates := friendly
+	if len(friendly) == 0 {
+		candidates = GetAllSolarSystems()
+	}
+	var midpoints []SolarSystem
+	for _, solarSystem := range candidates {
+		if strings.HasPrefix(solarSystem.ID, newEdenIDPrefix) && !IsHighsec(solarSystem) {
+			midpoints = append(midpoints, solarSystem)
+		}
+	}
+
+	var targets []SolarSystem
+	seen := make(map[string]bool)
+	for _, destination := range destinations {
+		if seen[destination.ID] {
+			continue
+		}
+		seen[destination.ID] = true
+		if IsHighsec(destination) {
+			plan.Unreachable = append(plan.Unreachable, destination.Name+" is highsec")
+			continue
+		}
+		if destination.ID == origin.ID {
+			plan.Chains = append(plan.Chains, CynoChain{Destination: destination, Steps: []RouteStep{}})
+			continue
+		}
+		targets = append(targets, destination)
+	}
+
+	graph := newCynoGraph(origin, targets, midpoints, ShipRanges[profileName])
+	reachable := graph.reachable()
+	var terminals []int
+	for i, destination := range targets {
+		if !reachable[i+1] {
+			plan.Unreachable = append(plan.Unreachable, fmt.Sprintf("%s is out of %s range", destination.Name, profileName))
+			continue
+		}
+		terminals = append(terminals, i+1)
+	}
+
+	if len(terminals) > 0 {
+		var parents []int
+		if len(terminals) <= maxExactCynoDestinations {
+			parents = graph.fewestMidpoints(terminals)
+		} else {
+			parents = graph.bestOrderedPlan(terminals)
+		}
+		planned := make(map[string]bool)
+		for _, terminal := range terminals {
+			steps := graph.chain(parents, terminal)
+			for _, step := range steps {
+				if to := graph.index[step.To.ID]; graph.weights[to] > 0 && !planned[step.To.ID] {
+					planned[step.To.ID] = true
+					plan.Midpoints = append(plan.Midpoints, step.To)
+				}
+			}
+			plan.Chains = append(plan.Chains, CynoChain{Destination: graph.systems[terminal], Steps: steps})
+		}
+	}
+
+	sort.Slice(plan.Midpoints, func(i, j int) bool {
+		return plan.Midpoints[i].Name < plan.Midpoints[j].Name
+	})
+	sort.Slice(plan.Chains, func(i, j int) bool {
+		return plan.Chains[i].Destination.Name < plan.Chains[j].Destination.Name
+	})
+	return plan, nil
+}
+
+// cynoGraph the systems a cyno plan can land in and which are in jump range of each other. The origin is first,
+// then the destinations, then the midpoints that aren't either.
+type cynoGraph struct {
+	systems   []SolarSystem
+	index     map[string]int
+	neighbors [][]int
+	// weights what landing in a system costs on top of the jump, midpointCost for a midpoint
+	weights []float64
+}
+
+func newCynoGraph(origin SolarSystem, destinations []SolarSystem, midpoints []SolarSystem, jumpRange float64) *cynoGraph {
+	graph := &cynoGraph{index: make(map[string]int)}
+	add := func(solarSystem SolarSystem, weight float64) {
+		if _, found := graph.index[solarSystem.ID]; found {
+			return
+		}
+		graph.index[solarSystem.ID] = len(graph.systems)
+		graph.systems = append(graph.systems, solarSystem)
+		graph.weights = append(graph.weights, weight)
+	}
+	add(origin, 0)
+	for _, destination := range destinations {
+		add(destination, 0)
+	}
+	for _, midpoint := range midpoints {
+		add(midpoint, midpointCost)
+	}
+
+	graph.neighbors = make([][]int, len(graph.systems))
+	maxSquared := jumpRange * jumpRange
+	for i := range graph.systems {
+		for j := i + 1; j < len(graph.systems); j++ {
+			if squaredDistance(graph.systems[i].Coordinates, graph.systems[j].Coordinates) <= maxSquared {
+				graph.neighbors[i] = append(graph.neighbors[i], j)
+				graph.neighbors[j] = append(graph.neighbors[j], i)
+			}
+		}
+	}
+	return graph
+}
+
+// landingCost what landing in a system adds to a plan, the origin is where the fleet starts so it never adds any.
+func (graph *cynoGraph) landingCost(system int) float64 {
+	if system == 0 {
+		return 0
+	}
+	return 1 + graph.weights[system]
+}
+
+// reachable the systems a chain of jumps from the origin can get to.
+func (graph *cynoGraph) reachable() []bool {
+	reached := make([]bool, len(graph.systems))
+	reached[0] = true
+	queue := []int{0}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range graph.neighbors[current] {
+			if !reached[next] {
+				reached[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return reached
+}
+
+// fewestMidpoints the cheapest tree of jumps from the origin to every terminal, as the system each one is jumped
+// to from. It is the Dreyfus-Wagner search for a Steiner tree: the cheapest tree joining each subset of the
+// terminals to each system is built from the trees of smaller subsets, then grown outwards jump by jump.
+func (graph *cynoGraph) fewestMidpoints(terminals []int) []int {
+	type source struct {
+		// split the subset joined with its complement at this system, grown from the system when zero
+		split int
+		from  int
+	}
+	full := 1<<len(terminals) - 1
+	costs := make([][]float64, full+1)
+	sources := make([][]source, full+1)
+	for subset := 1; subset <= full; subset++ {
+		costs[subset] = make([]float64, len(graph.systems))
+		sources[subset] = make([]source, len(graph.systems))
+		for system := range costs[subset] {
+			costs[subset][system] = math.Inf(1)
+			sources[subset][system] = source{from: -1}
+		}
+	}
+
+	for subset := 1; subset <= full; subset++ {
+		subsetCosts, subsetSources := costs[subset], sources[subset]
+		if subset&(subset-1) == 0 {
+			terminal := terminals[bits.TrailingZeros(uint(subset))]
+			subsetCosts[terminal] = graph.landingCost(terminal)
+		}
+		// Each split once, the part holding the lowest terminal first
+		lowest := subset & -subset
+		for part := (subset - 1) & subset; part > 0; part = (part - 1) & subset {
+			if part&lowest == 0 {
+				continue
+			}
+			for system := range graph.systems {
+				cost := costs[part][system] + costs[subset^part][system] - graph.landingCost(system)
+				if cost < subsetCosts[system] {
+					subsetCosts[system] = cost
+					subsetSources[system] = source{split: part, from: -1}
+				}
+			}
+		}
+
+		queue := &routeQueue{}
+		for system, cost := range subsetCosts {
+			if !math.IsInf(cost, 1) {
+				heap.Push(queue, routeQueueItem{systemID: graph.systems[system].ID, cost: cost})
+			}
+		}
+		for queue.Len() > 0 {
+			current := heap.Pop(queue).(routeQueueItem)
+			system := graph.index[current.systemID]
+			if current.cost > subsetCosts[system] {
+				continue
+			}
+			for _, next := range graph.neighbors[system] {
+				cost := current.cost + graph.landingCost(next)
+				if cost < subsetCosts[next] {
+					subsetCosts[next] = cost
+					subsetSources[next] = source{from: system}
+					heap.Push(queue, routeQueueItem{systemID: graph.systems[next].ID, cost: cost})
+				}
+			}
+		}
+	}
+
+	// Walk back through the sources for the jumps in the tree, then root them at the origin
+	jumps := make([][]int, len(graph.systems))
+	var collect func(subset int, system int)
+	collect = func(subset int, system int) {
+		switch from := sources[subset][system]; {
+		case from.from >= 0:
+			jumps[system] = append(jumps[system], from.from)
+			jumps[from.from] = append(jumps[from.from], system)
+			collect(subset, from.from)
+		case from.split > 0:
+			collect(from.split, system)
+			collect(subset^from.split, system)
+		}
+	}
+	collect(full, 0)
+
+	parents := make([]int, len(graph.systems))
+	for system := range parents {
+		parents[system] = -1
+	}
+	parents[0] = 0
+	queue := []int{0}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range jumps[current] {
+			if parents[next] < 0 {
+				parents[next] = current
+				queue = append(queue, next)
+			}
+		}
+	}
+	return parents
+}
+
+// bestOrderedPlan the cheapest of planning the terminals one at a time in several orders, furthest from the origin
+// first, nearest first and turns of the furthest first order.
+func (graph *cynoGraph) bestOrderedPlan(terminals []int) []int {
+	furthestFirst := append([]int(nil), terminals...)
+	sort.SliceStable(furthestFirst, func(i, j int) bool {
+		return squaredDistance(graph.systems[0].Coordinates, graph.systems[furthestFirst[i]].Coordinates) >
+			squaredDistance(graph.systems[0].Coordinates, graph.systems[furthestFirst[j]].Coordinates)
+	})
+	nearestFirst := make([]int, len(furthestFirst))
+	for i, terminal := range furthestFirst {
+		nearestFirst[len(furthestFirst)-1-i] = terminal
+	}
+	orders := [][]int{furthestFirst, nearestFirst}
+	for turn := 1; turn < cynoFallbackOrders-1; turn++ {
+		start := turn * len(furthestFirst) / (cynoFallbackOrders - 1)
+		orders = append(orders, append(append([]int(nil), furthestFirst[start:]...), furthestFirst[:start]...))
+	}
+
+	var best []int
+	bestCost := math.Inf(1)
+	for _, order := range orders {
+		parents, cost := graph.orderedPlan(order)
+		if cost < bestCost {
+			best, bestCost = parents, cost
+		}
+	}
+	return best
+}
+
+// orderedPlan plans the terminals in order, each with the fewest new midpoints then jumps it needs to branch off
+// the systems already planned. It returns the system each one is jumped to from and the cost of the whole plan.
+func (graph *cynoGraph) orderedPlan(order []int) ([]int, float64) {
+	parents := make([]int, len(graph.systems))
+	for system := range parents {
+		parents[system] = -1
+	}
+	parents[0] = 0
+	var total float64
+	for _, terminal := range order {
+		if parents[terminal] >= 0 {
+			continue
+		}
+		costs := make(map[int]float64)
+		previous := make(map[int]int)
+		queue := &routeQueue{}
+		for system, parent := range parents {
+			if parent >= 0 {
+				costs[system] = 0
+				heap.Push(queue, routeQueueItem{systemID: graph.systems[system].ID})
+			}
+		}
+		for queue.Len() > 0 {
+			current := heap.Pop(queue).(routeQueueItem)
+			system := graph.index[current.systemID]
+			if current.cost > costs[system] {
+				continue
+			}
+			if system == terminal {
+				total += current.cost
+				for ; parents[system] < 0; system = previous[system] {
+					parents[system] = previous[system]
+				}
+				break
+			}
+			for _, next := range graph.neighbors[system] {
+				if parents[next] >= 0 {
+					continue
+				}
+				cost := current.cost + graph.landingCost(next)
+				if known, visited := costs[next]; visited && known <= cost {
+					continue
+				}
+				costs[next] = cost
+				previous[next] = system
+				heap.Push(queue, routeQueueItem{systemID: graph.systems[next].ID, cost: cost})
+			}
+		}
+	}
+	return parents, total
+}
+
+// chain the jumps from the origin to a system along the planned tree.
+func (graph *cynoGraph) chain(parents []int, system int) []RouteStep {
+	steps := []RouteStep{}
+	for ; system != 0; system = parents[system] {
+		steps = append([]RouteStep{newRouteStep(graph.systems[parents[system]], graph.systems[system], RouteStepJump)}, steps...)
+	}
+	return steps
+}
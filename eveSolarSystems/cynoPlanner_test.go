@@ -0,0 +1,83 @@
+package eveSolarSystems
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPlanCynos(t *testing.T) {
+	tests := []struct {
+		name         string
+		destinations []string
+		friendly     []string
+		midpoints    []string
+		chains       map[string]string
+		unreachable  []string
+	}{
+		// Mike is the quickest way to Delta but Golf still needs Hotel, which gets to Delta through Echo anyway
+		{"one midpoint shared", []string{"Delta", "Echo", "Golf"}, nil, []string{"Hotel"},
+			map[string]string{"Delta": "Hotel, Echo, Delta", "Echo": "Hotel, Echo", "Golf": "Hotel, Golf"}, []string{}},
+		// Hotel isn't friendly so nothing gets to Golf
+		{"friendly midpoints only", []string{"Delta", "Golf"}, []string{"Mike"}, []string{"Mike"},
+			map[string]string{"Delta": "Mike, Delta"}, []string{"Golf is out of Supers range"}},
+		{"unreachable", []string{"Jita", "Sierra", "Golf"}, nil, []string{"Hotel"},
+			map[string]string{"Golf": "Hotel, Golf"}, []string{"Jita is highsec", "Sierra is out of Supers range"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var destinations, friendly []SolarSystem
+			for _, name := range test.destinations {
+				destinations = append(destinations, findTestSystem(t, name))
+			}
+			for _, name := range test.friendly {
+				friendly = append(friendly, findTestSystem(t, name))
+			}
+			plan, err := PlanCynos(findTestSystem(t, "Oscar"), destinations, "supers", friendly)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var midpoints []string
+			for _, midpoint := range plan.Midpoints {
+				midpoints = append(midpoints, midpoint.Name)
+			}
+			chains := make(map[string]string)
+			for _, chain := range plan.Chains {
+				var steps []string
+				for _, step := range chain.Steps {
+					steps = append(steps, step.To.Name)
+				}
+				chains[chain.Destination.Name] = strings.Join(steps, ", ")
+			}
+			if !reflect.DeepEqual(midpoints, test.midpoints) || !reflect.DeepEqual(chains, test.chains) || !reflect.DeepEqual(plan.Unreachable, test.unreachable) {
+				t.Errorf("got midpoints %v chains %v unreachable %v, want %v %v %v", midpoints, chains, plan.Unreachable,
+					test.midpoints, test.chains, test.unreachable)
+			}
+		})
+	}
+}
+
+func TestCynoGraphOrderedPlan(t *testing.T) {
+	var midpoints []SolarSystem
+	for _, name := range []string{"Hotel", "Mike"} {
+		midpoints = append(midpoints, findTestSystem(t, name))
+	}
+	destinations := []SolarSystem{findTestSystem(t, "Delta"), findTestSystem(t, "Echo"), findTestSystem(t, "Golf")}
+	graph := newCynoGraph(findTestSystem(t, "Oscar"), destinations, midpoints, ShipRanges["Supers"])
+
+	// Furthest first lands in Mike on the way to Delta, then Golf needs Hotel as well
+	_, furthestFirst := graph.orderedPlan([]int{1, 2, 3})
+	if furthestFirst != 2*midpointCost+5 {
+		t.Errorf("furthest first costs %v, want two midpoints and five jumps", furthestFirst)
+	}
+	// Nearest first is one of the orders tried and finds the single midpoint
+	parents := graph.bestOrderedPlan([]int{1, 2, 3})
+	var steps []string
+	for _, step := range graph.chain(parents, 1) {
+		steps = append(steps, step.To.Name)
+	}
+	if got := strings.Join(steps, ", "); got != "Hotel, Echo, Delta" {
+		t.Errorf("got %s to Delta, want Hotel, Echo, Delta", got)
+	}
+}
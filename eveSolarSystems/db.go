@@ -9,17 +9,33 @@ import (
 	"log"
 	"os"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 const (
 	dbFile               string = "eveSolarSystems/tracker.db"
 	solarSystemsBucket   string = "solarSystems"
 	stagingSystemsBucket string = "stagingSystems"
+	sovereigntyBucket    string = "sovereignty"
+	namesBucket          string = "names"
+	settingsBucket       string = "settings"
 )
 
-func init() {
+// setupDBOnce builds the buckets the first time the DB is opened rather than when the package loads, so commands
+// that never touch the DB don't need the data files.
+var setupDBOnce sync.Once
+
+// openDB opens the DB, building the solar system and other buckets first if this is the first time.
+func openDB() (*bolt.DB, error) {
+	setupDBOnce.Do(setupDB)
+	return bolt.Open(dbFile, 0600, nil)
+}
+
+// setupDB builds the solar system bucket from the CSV and creates every other bucket.
+func setupDB() {
 	db, err := bolt.Open(dbFile, 0600, nil)
 	if err != nil {
 		log.Fatal(err)
@@ -44,15 +60,25 @@ func init() {
 		if err != nil {
 			return err
 		}
+		for _, name := range []string{sovereigntyBucket, namesBucket, settingsBucket, stagingListsBucket, subscriptionsBucket, jumpBridgesBucket, locationHistoryBucket} {
+			if _, err = tx.CreateBucketIfNotExists([]byte(name)); err != nil {
+				return err
+			}
+		}
+		// Stargates are read again until there are some, so dropping in the stargates file later is enough
+		bucket, err = tx.CreateBucketIfNotExists([]byte(stargatesBucket))
+		if err != nil {
+			return err
+		}
+		if key, _ := bucket.Cursor().First(); key == nil {
+			return buildStargateBucket(bucket)
+		}
 		return nil
 	})
 
 	if err != nil {
 		panic(fmt.Sprintf("Error building Solar System DB: %s", err))
 	}
-	if err != nil {
-		log.Fatal(err)
-	}
 }
 
 func GetSystemByID(id string) SolarSystem {
@@ -60,7 +86,7 @@ func GetSystemByID(id string) SolarSystem {
 		return SolarSystem{}
 	}
 
-	db, err := bolt.Open(dbFile, 0600, nil)
+	db, err := openDB()
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -104,7 +130,7 @@ func GetSystemByID(id string) SolarSystem {
 func GetSystemByName(name string) SolarSystem {
 	var retrievedSolarSystem SolarSystem
 
-	db, err := bolt.Open(dbFile, 0600, nil)
+	db, err := openDB()
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -145,7 +171,7 @@ func GetSystemByName(name string) SolarSystem {
 func GetAllSystems() []string {
 	var solarSystems []string
 
-	db, err := bolt.Open(dbFile, 0600, nil)
+	db, err := openDB()
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -178,7 +204,7 @@ func GetAllSystems() []string {
 }
 
 func GetStagingSystems() map[string]string {
-	db, err := bolt.Open(dbFile, 0600, nil)
+	db, err := openDB()
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -209,7 +235,7 @@ func GetStagingSystems() map[string]string {
 }
 
 func UpdateStagingSystems(stagingSystems map[string]string) error {
-	db, err := bolt.Open(dbFile, 0600, nil)
+	db, err := openDB()
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -244,46 +270,104 @@ func UpdateStagingSystems(stagingSystems map[string]string) error {
 	return nil
 }
 
-// GetStagingsInRange Get all user inputted stagings in range.
-func GetStagingsInRange(currentSystemData Coordinates, jumpRange float64) map[string]string {
-	db, err := bolt.Open(dbFile, 0600, nil)
+// GetSetting returns a saved app setting, empty if it was never saved.
+func GetSetting(key string) string {
+	db, err := openDB()
 	if err != nil {
 		log.Fatal(err)
 	}
 	defer db.Close()
 
-	stagingInRange := make(map[string]string)
-	systemsInRange := getSystemsInRange(currentSystemData, jumpRange, db)
+	var value string
 	err = db.View(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte(stagingSystemsBucket))
+		bucket := tx.Bucket([]byte(settingsBucket))
 		if bucket == nil {
 			return fmt.Errorf("bucket not found")
 		}
-		err = bucket.ForEach(func(system, owner []byte) error {
-			if _, exists := systemsInRange[strings.ToLower(string(system))]; exists {
-				stagingInRange[string(system)] = string(owner)
-			}
-			return nil
-		})
+		value = string(bucket.Get([]byte(key)))
+		return nil
+	})
 
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return value
+}
+
+// SaveSetting saves an app setting so it is reused each time the app is opened.
+func SaveSetting(key string, value string) error {
+	db, err := openDB()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	return db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(settingsBucket))
 		if err != nil {
-			log.Fatal(err)
+			return err
 		}
+		return bucket.Put([]byte(key), []byte(value))
+	})
+}
 
-		return nil
+// GetStagingsInRange Get all user inputted stagings in range, from every staging list.
+func GetStagingsInRange(currentSystemData Coordinates, jumpRange float64) []StagingInRange {
+	// Loaded before opening the DB as the first load opens it too
+	regions := loadRegionIndex()
+	gateJumps := gateDistancesFrom(currentSystemData)
+
+	db, err := openDB()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	var stagingInRange []StagingInRange
+	systemsInRange := getSystemsInRange(currentSystemData, jumpRange, db)
+	err = db.View(func(tx *bolt.Tx) error {
+		sovBucket := tx.Bucket([]byte(sovereigntyBucket))
+		return forEachStagingList(tx, func(list string, bucket *bolt.Bucket) error {
+			return bucket.ForEach(func(system, owner []byte) error {
+				if solarSystem, exists := systemsInRange[strings.ToLower(string(system))]; exists {
+					staging := StagingInRange{
+						System:     solarSystem,
+						List:       list,
+						Region:     regions[solarSystem.ID],
+						Owner:      string(owner),
+						LightYears: ToLightYears(Distance3D(currentSystemData, solarSystem.Coordinates)),
+						GateJumps:  NoGateRoute,
+						Activity:   GetSystemActivity(solarSystem.ID),
+					}
+					if jumps, reachable := gateJumps[solarSystem.ID]; reachable {
+						staging.GateJumps = jumps
+					}
+					if sovBucket != nil {
+						staging.Sov = string(sovBucket.Get([]byte(solarSystem.ID)))
+					}
+					stagingInRange = append(stagingInRange, staging)
+				}
+				return nil
+			})
+		})
 	})
 
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	sort.SliceStable(stagingInRange, func(i, j int) bool {
+		return stagingInRange[i].System.Name < stagingInRange[j].System.Name
+	})
+
 	return stagingInRange
 }
 
-// getSystemsInRange used to get systems in a range from current system.
+// getSystemsInRange used to get systems in a range from current system keyed by lower case name.
 // Only used in GetStagingsInRange to get staging in range.
-func getSystemsInRange(currentSystemData Coordinates, jumpRange float64, db *bolt.DB) map[string]struct{} {
-	systemsInRange := make(map[string]struct{})
+func getSystemsInRange(currentSystemData Coordinates, jumpRange float64, db *bolt.DB) map[string]SolarSystem {
+	systemsInRange := make(map[string]SolarSystem)
 	err := db.View(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(solarSystemsBucket))
 		if bucket == nil {
@@ -296,7 +380,7 @@ func getSystemsInRange(currentSystemData Coordinates, jumpRange float64, db *bol
 				return err
 			}
 			if solarSystem.Coordinates != currentSystemData && Distance3D(currentSystemData, solarSystem.Coordinates) <= jumpRange {
-				systemsInRange[strings.ToLower(solarSystem.Name)] = struct{}{}
+				systemsInRange[strings.ToLower(solarSystem.Name)] = solarSystem
 			}
 			return nil
 		})
@@ -0,0 +1,50 @@
+package eveSolarSystems
+
+import (
+	"github.com/sythe7448/Eve-Sonar/internal/testutil"
+	"testing"
+)
+
+// testSystems a small map. Alpha gates through nullsec Nova, November and Niner to lowsec Zulu, and friendly
+// Foxtrot gates straight into Zulu. Jita to Sierra are far away for jump bridge tests, and Oscar to Golf further
+// still for cyno plans.
+var testSystems = []testutil.System{
+	{ID: "30000001", Name: "Alpha", X: 0, Y: 0, Sec: -0.3},
+	{ID: "30000002", Name: "Nova", X: 1, Y: -2, Sec: -0.4},
+	{ID: "30000003", Name: "November", X: 3, Y: -3, Sec: -0.5},
+	{ID: "30000004", Name: "Niner", X: 5, Y: -2, Sec: -0.4},
+	{ID: "30000005", Name: "Zulu", X: 6, Y: 0, Sec: 0.3},
+	{ID: "30000006", Name: "Foxtrot", X: 3, Y: 2, Sec: -0.2},
+	{ID: "30000007", Name: "Jita", X: 40, Y: 0, Sec: 0.9},
+	{ID: "30000008", Name: "Old Man Star", X: 42, Y: 0, Sec: 0.3},
+	{ID: "30000009", Name: "Nova Prime", X: 30, Y: 30, Sec: -0.6},
+	{ID: "30000010", Name: "Sierra", X: 32, Y: 30, Sec: -0.7},
+	{ID: "30000011", Name: "Oscar", X: 100, Y: 0, Sec: -0.2},
+	{ID: "30000012", Name: "Hotel", X: 105, Y: 0, Sec: -0.3},
+	{ID: "30000013", Name: "Mike", X: 105.4, Y: 2.4, Sec: -0.3},
+	{ID: "30000014", Name: "Delta", X: 111, Y: 4, Sec: -0.4},
+	{ID: "30000015", Name: "Echo", X: 110, Y: 0, Sec: -0.4},
+	{ID: "30000016", Name: "Golf", X: 105, Y: -5.5, Sec: -0.5},
+}
+
+var testStargates = [][2]string{
+	{"30000001", "30000002"},
+	{"30000002", "30000003"},
+	{"30000003", "30000004"},
+	{"30000004", "30000005"},
+	{"30000006", "30000005"},
+}
+
+func TestMain(m *testing.M) {
+	testutil.Main(m, testSystems, testStargates, WaitForRangeChecks)
+}
+
+// findTestSystem looks up a test system by name, failing the test when it isn't there.
+func findTestSystem(t *testing.T, name string) SolarSystem {
+	t.Helper()
+	solarSystem, found := FindSystemByName(name)
+	if !found {
+		t.Fatalf("no test system %q", name)
+	}
+	return solarSystem
+}
@@ -4,19 +4,41 @@ import (
 	"fmt"
 	"math"
 	"math/big"
-	"reflect"
 	"strings"
 )
 
 type SolarSystem struct {
-	ID          string
-	Name        string
-	Coordinates Coordinates
-	Sec         float64
+	ID          string      `json:"id"`
+	Name        string      `json:"name"`
+	Coordinates Coordinates `json:"coordinates"`
+	Sec         float64     `json:"security"`
 }
 
 type Coordinates struct {
-	X, Y, Z float64
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+}
+
+// StagingInRange a user inputted staging along with the current sovereignty holder and activity of its system.
+type StagingInRange struct {
+	System     SolarSystem `json:"system"`
+	List       string      `json:"list"`
+	Region     string      `json:"region"`
+	Owner      string      `json:"owner"`
+	Sov        string      `json:"sov"`
+	LightYears float64     `json:"light_years"`
+	// GateJumps the shortest stargate route, NoGateRoute when there isn't one or no stargate data
+	GateJumps int            `json:"gate_jumps"`
+	Activity  SystemActivity `json:"activity"`
+	// Fuel the isotopes to jump there with the hull flown for the range profile, only set for range results
+	Fuel *FuelCost `json:"fuel,omitempty"`
+}
+
+// RangeResult the stagings in range of a system for one range option.
+type RangeResult struct {
+	Profile  string           `json:"profile"`
+	Stagings []StagingInRange `json:"stagings"`
 }
 
 const (
@@ -24,74 +46,135 @@ const (
 	superCapitalLightYears float64 = 56764382835480260
 	industryLightYears     float64 = 94607304725800420
 	blopsLightYears        float64 = 75685843780640350
+	metersPerLightYear     float64 = 9460730472580800
 )
 
-// GetStagingSystemsBySelectedRangeText Creates a text block to display staging systems based on selected ranges.
-func GetStagingSystemsBySelectedRangeText(shipRangesSettings ShipRangeSettings, currentSolarSystem SolarSystem) string {
-	shipRangesMap := map[string]float64{
-		"Blops":    blopsLightYears,
-		"Supers":   superCapitalLightYears,
-		"Capitals": capitalLightYears,
-		"Industry": industryLightYears,
-	}
-
-	returnText := ""
-	shipRanges := reflect.ValueOf(shipRangesSettings)
-	for i := 0; i < shipRanges.NumField(); i++ {
-		field := shipRanges.Field(i)
-		if field.Bool() {
-			fieldString := fmt.Sprintf("%s", shipRanges.Type().Field(i).Name)
-			stagingsInRange := GetStagingsInRange(currentSolarSystem.Coordinates, shipRangesMap[fieldString])
-			returnText += fmt.Sprintf("Staging Systems in %s range:\n", fieldString)
-			for s, o := range stagingsInRange {
-				if s == "" {
-					returnText += fmt.Sprintf("No Staging System are in range of %s", fieldString)
-				}
-				returnText += fmt.Sprintf("%s: %s\n", s, o)
-			}
-			returnText += "\n"
+// ShipRangeNames the jump range options in the order they are shown.
+var ShipRangeNames = []string{"Blops", "Supers", "Capitals", "Industry"}
+
+// ShipRanges the jump range in meters of each range option.
+var ShipRanges = map[string]float64{
+	"Blops":    blopsLightYears,
+	"Supers":   superCapitalLightYears,
+	"Capitals": capitalLightYears,
+	"Industry": industryLightYears,
+}
+
+// GetRangeResults the stagings in range of a system for each of the given range options, with the fuel to jump
+// to each.
+func GetRangeResults(solarSystem SolarSystem, rangeNames []string) []RangeResult {
+	fuelSettings := GetFuelSettings()
+	results := []RangeResult{}
+	for _, rangeName := range rangeNames {
+		stagings := GetStagingsInRange(solarSystem.Coordinates, ShipRanges[rangeName])
+		if stagings == nil {
+			stagings = []StagingInRange{}
 		}
+		addStagingFuel(stagings, rangeName, fuelSettings)
+		results = append(results, RangeResult{Profile: rangeName, Stagings: stagings})
 	}
+	return results
+}
 
-	return returnText
+// RejectedLine a line of staging text that couldn't be used and why.
+type RejectedLine struct {
+	Line   int    `json:"line"`
+	Text   string `json:"text"`
+	Reason string `json:"reason"`
 }
 
-// ConvertStagingSystemsToSting converts map back to user input text. Used on app load.
-func ConvertStagingSystemsToSting() string {
-	systemsString := ""
-	stagingSystemsMap := GetStagingSystems()
-	if len(stagingSystemsMap) != 0 {
-		for system, owner := range stagingSystemsMap {
-			systemsString += fmt.Sprintf("%s:%s\n", system, owner)
+// ParseStagingText parses system:note lines, splitting on the first colon so notes can contain colons.
+// Blank lines are skipped and a line without a colon is a system with no note.
+func ParseStagingText(stagingSystemsText string) ([]StagingEntry, []RejectedLine) {
+	var entries []StagingEntry
+	var rejected []RejectedLine
+	seen := make(map[string]int)
+	for i, line := range strings.Split(stagingSystemsText, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		system, note, _ := strings.Cut(line, ":")
+		solarSystem, err := ValidateStagingSystem(system)
+		if err != nil {
+			rejected = append(rejected, RejectedLine{Line: i + 1, Text: line, Reason: err.Error()})
+			continue
+		}
+		if firstLine, duplicate := seen[solarSystem.ID]; duplicate {
+			rejected = append(rejected, RejectedLine{Line: i + 1, Text: line, Reason: fmt.Sprintf("%s is already on line %d", solarSystem.Name, firstLine)})
+			continue
 		}
+		seen[solarSystem.ID] = i + 1
+		entries = append(entries, StagingEntry{System: solarSystem.Name, Note: strings.TrimSpace(note)})
 	}
-	return systemsString
+	return entries, rejected
 }
 
-// ParseAndSaveStagingSystems parse user input and validate it before sending to be saved to bolt
-func ParseAndSaveStagingSystems(stagingSystemsText string) {
-	if stagingSystemsText == "" {
-		err := UpdateStagingSystems(nil)
-		if err != nil {
-			return
+// ValidateStagingSystem finds the system for a staging, suggesting a system when the name is only a prefix.
+func ValidateStagingSystem(systemName string) (SolarSystem, error) {
+	systemName = strings.TrimSpace(systemName)
+	if systemName == "" {
+		return SolarSystem{}, fmt.Errorf("system name is blank")
+	}
+	if solarSystem, found := FindSystemByName(systemName); found {
+		return solarSystem, nil
+	}
+	if suggestions := SearchSystems(systemName); len(suggestions) > 0 {
+		return SolarSystem{}, fmt.Errorf("unknown system %q, did you mean %s?", systemName, suggestions[0].Name)
+	}
+	return SolarSystem{}, fmt.Errorf("unknown system %q", systemName)
+}
+
+// AddStagingSystem validates the system name and saves it as a staging with a note in a list, replacing any existing note.
+// The list is created if it doesn't exist.
+func AddStagingSystem(list string, systemName string, note string) (SolarSystem, error) {
+	solarSystem, err := ValidateStagingSystem(systemName)
+	if err != nil {
+		return SolarSystem{}, err
+	}
+	list = strings.TrimSpace(list)
+	if existing, found := FindStagingList(list); found {
+		list = existing
+	}
+
+	stagingSystemsMap := GetStagingList(list)
+	for system := range stagingSystemsMap {
+		if strings.EqualFold(system, solarSystem.Name) {
+			delete(stagingSystemsMap, system)
 		}
 	}
-	lines := strings.Split(stagingSystemsText, "\n")
-	stagingSystemsMap := make(map[string]string)
-	for _, line := range lines {
-		parts := strings.Split(line, ":")
-		if len(parts) == 2 {
-			// Make sure system exists to be added
-			exists := GetSystemByName(parts[0]).Name
-			if len(exists) > 0 {
-				stagingSystemsMap[parts[0]] = parts[1]
-			}
+	stagingSystemsMap[solarSystem.Name] = note
+	if err := UpdateStagingList(list, stagingSystemsMap); err != nil {
+		return SolarSystem{}, err
+	}
+	recheckRangesInBackground()
+
+	return solarSystem, nil
+}
+
+// RemoveStagingSystem removes a staging from a list by system name, case insensitive.
+func RemoveStagingSystem(listName string, systemName string) error {
+	list, found := FindStagingList(listName)
+	if !found {
+		return fmt.Errorf("unknown staging list %q", listName)
+	}
+	stagingSystemsMap := GetStagingList(list)
+	removed := false
+	for system := range stagingSystemsMap {
+		if strings.EqualFold(system, strings.TrimSpace(systemName)) {
+			delete(stagingSystemsMap, system)
+			removed = true
 		}
 	}
-	err := UpdateStagingSystems(stagingSystemsMap)
-	if err != nil {
-		return
+	if !removed {
+		return fmt.Errorf("%q is not a staging system in %s", systemName, list)
 	}
+	if err := UpdateStagingList(list, stagingSystemsMap); err != nil {
+		return err
+	}
+	recheckRangesInBackground()
+
+	return nil
 }
 
 // Distance3D calculate the distance in 3d space between 2 points
@@ -103,6 +186,37 @@ func Distance3D(p1, p2 Coordinates) float64 {
 	return math.Sqrt(dx*dx + dy*dy + dz*dz)
 }
 
+// FindShipRange matches a range option by name, case insensitive and allowing singular names like "capital".
+func FindShipRange(name string) (string, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, rangeName := range ShipRangeNames {
+		lowerRangeName := strings.ToLower(rangeName)
+		if name == lowerRangeName || name+"s" == lowerRangeName {
+			return rangeName, true
+		}
+	}
+	return "", false
+}
+
+// IsHighsec systems that round to 0.5 security or above, jump drives can't be used in them.
+func IsHighsec(solarSystem SolarSystem) bool {
+	return solarSystem.Sec >= 0.45
+}
+
+// DisplaySecurity the security status as the game shows it, rounded to one decimal with anything above 0.0 shown as at least 0.1.
+func DisplaySecurity(sec float64) float64 {
+	if sec > 0 && sec < 0.05 {
+		return 0.1
+	}
+	// Adding zero turns the -0 of rounding small negatives into 0
+	return math.Round(sec*10)/10 + 0
+}
+
+// ToLightYears converts a distance in meters to light years.
+func ToLightYears(meters float64) float64 {
+	return meters / metersPerLightYear
+}
+
 // bigMathSub this helper is required as the floats are too big to do just math on
 func bigMathSub(x float64, y float64) float64 {
 	xBig := new(big.Float).SetPrec(256).SetFloat64(x)
@@ -112,3 +226,23 @@ func bigMathSub(x float64, y float64) float64 {
 
 	return float64Result
 }
+
+// splitCommaList splits a comma separated input, ignoring blanks.
+func splitCommaList(text string) []string {
+	var values []string
+	for _, value := range strings.Split(text, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,193 @@
+package eveSolarSystems
+
+import (
+	"github.com/sythe7448/Eve-Sonar/api"
+	"sync"
+	"time"
+)
+
+// Event something changed that overlays, bots and notifications may want to react to.
+type Event struct {
+	Type       string              `json:"type"`
+	Time       time.Time           `json:"time"`
+	Character  string              `json:"character,omitempty"`
+	Source     string              `json:"source,omitempty"`
+	Location   *SolarSystem        `json:"location,omitempty"`
+	Profile    string              `json:"profile,omitempty"`
+	Staging    *StagingInRange     `json:"staging,omitempty"`
+	TokenState string              `json:"token_state,omitempty"`
+	List       string              `json:"list,omitempty"`
+	Sync       *SubscriptionChange `json:"sync,omitempty"`
+	Test       bool                `json:"test,omitempty"`
+}
+
+// Event types
+const (
+	EventCharacterMoved      = "character_moved"
+	EventStagingEnteredRange = "staging_entered_range"
+	EventStagingLeftRange    = "staging_left_range"
+	EventTokenStateChanged   = "token_state_changed"
+	EventStagingListSynced   = "staging_list_synced"
+)
+
+// EventTypes every event type, in the order they are documented.
+var EventTypes = []string{EventCharacterMoved, EventStagingEnteredRange, EventStagingLeftRange, EventTokenStateChanged, EventStagingListSynced}
+
+// Events the bus every change event is published on.
+var Events = NewEventBus()
+
+// EventBus fans published events out to every subscriber.
+type EventBus struct {
+	subscribers map[chan Event]struct{}
+	lock        sync.Mutex
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{subscribers: make(map[chan Event]struct{})}
+}
+
+// Publish sends an event to every subscriber, subscribers that have fallen too far behind miss it.
+func (b *EventBus) Publish(event Event) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	for subscriber := range b.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel receiving every event published from now on.
+func (b *EventBus) Subscribe() <-chan Event {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	subscriber := make(chan Event, subscriberBuffer)
+	b.subscribers[subscriber] = struct{}{}
+
+	return subscriber
+}
+
+// Unsubscribe stops and closes a channel returned by Subscribe.
+func (b *EventBus) Unsubscribe(subscription <-chan Event) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	for subscriber := range b.subscribers {
+		if subscriber == subscription {
+			delete(b.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+}
+
+// inRangeByCharacter the stagings last seen in range of each character, by range profile then list and system ID.
+var inRangeByCharacter = make(map[string]map[string]map[string]StagingInRange)
+var inRangeLock sync.Mutex
+
+// rangeChecks the range checks running in the background, waited on by WaitForRangeChecks
+var rangeChecks sync.WaitGroup
+
+func init() {
+	api.OnTokenStateChange = func(state string) {
+		Events.Publish(Event{
+			Type:       EventTokenStateChanged,
+			Time:       time.Now(),
+			Character:  api.Character.CharacterName,
+			TokenState: state,
+		})
+	}
+
+	go func(locations <-chan LocationEvent) {
+		for location := range locations {
+			solarSystem, found := FindSystemByID(location.SolarSystemID)
+			if found {
+				Events.Publish(Event{
+					Type:      EventCharacterMoved,
+					Time:      location.Time,
+					Character: location.Character,
+					Source:    location.Source,
+					Location:  &solarSystem,
+				})
+				checkRangeChanges(location.Character, solarSystem)
+			}
+			rangeChecks.Done()
+		}
+	}(Tracker.SubscribeTracked(&rangeChecks))
+}
+
+// recheckRangesInBackground runs RecheckRanges without holding up the staging change that called it.
+func recheckRangesInBackground() {
+	rangeChecks.Add(1)
+	go func() {
+		defer rangeChecks.Done()
+		RecheckRanges()
+	}()
+}
+
+// WaitForRangeChecks blocks until the range checks running in the background are done with the DB.
+func WaitForRangeChecks() {
+	rangeChecks.Wait()
+}
+
+// RecheckRanges publishes range changes for every tracked character, used after the staging list changes.
+func RecheckRanges() {
+	for character, location := range Tracker.Locations() {
+		if solarSystem, found := FindSystemByID(location.SolarSystemID); found {
+			checkRangeChanges(character, solarSystem)
+		}
+	}
+}
+
+// checkRangeChanges compares the stagings in range of a character with last time and publishes the differences.
+func checkRangeChanges(character string, solarSystem SolarSystem) {
+	inRangeLock.Lock()
+	defer inRangeLock.Unlock()
+
+	previousByProfile, seen := inRangeByCharacter[character]
+	if !seen {
+		previousByProfile = make(map[string]map[string]StagingInRange)
+		inRangeByCharacter[character] = previousByProfile
+	}
+
+	now := time.Now()
+	for _, rangeName := range ShipRangeNames {
+		current := make(map[string]StagingInRange)
+		for _, staging := range GetStagingsInRange(solarSystem.Coordinates, ShipRanges[rangeName]) {
+			current[staging.List+"/"+staging.System.ID] = staging
+		}
+		previous := previousByProfile[rangeName]
+
+		for id, staging := range current {
+			if _, wasInRange := previous[id]; !wasInRange {
+				staging := staging
+				Events.Publish(Event{
+					Type:      EventStagingEnteredRange,
+					Time:      now,
+					Character: character,
+					Location:  &solarSystem,
+					Profile:   rangeName,
+					Staging:   &staging,
+				})
+			}
+		}
+		for id, staging := range previous {
+			if _, stillInRange := current[id]; !stillInRange {
+				staging := staging
+				staging.LightYears = ToLightYears(Distance3D(solarSystem.Coordinates, staging.System.Coordinates))
+				staging.GateJumps, _ = GateJumps(solarSystem, staging.System)
+				Events.Publish(Event{
+					Type:      EventStagingLeftRange,
+					Time:      now,
+					Character: character,
+					Location:  &solarSystem,
+					Profile:   rangeName,
+					Staging:   &staging,
+				})
+			}
+		}
+		previousByProfile[rangeName] = current
+	}
+}
@@ -0,0 +1,218 @@
+package eveSolarSystems
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// JumpHull a ship class with a jump drive and the isotopes it burns.
+type JumpHull struct {
+	Name string `json:"name"`
+	// Profile the range profile the hull jumps with
+	Profile string `json:"profile"`
+	// IsotopesPerLightYear the hull's jump drive fuel need before skills, rough as it differs a little between ships
+	IsotopesPerLightYear float64 `json:"isotopes_per_light_year"`
+	// JumpFreighter the Jump Freighters skill lowers its fuel need as well
+	JumpFreighter bool `json:"jump_freighter"`
+}
+
+// FuelSettings the pilot's skills and ships used to work out jump fuel.
+type FuelSettings struct {
+	// Race the race of the ships flown, each burns its own isotope
+	Race                 string `json:"race"`
+	JumpFuelConservation int    `json:"jump_fuel_conservation"`
+	JumpFreighters       int    `json:"jump_freighters"`
+	// Hulls the hull flown for each range profile, the first hull of the profile when not set
+	Hulls map[string]string `json:"hulls"`
+}
+
+// FuelCost the isotopes a jump or a route burns.
+type FuelCost struct {
+	Hull     string  `json:"hull"`
+	Isotope  string  `json:"isotope"`
+	Isotopes int     `json:"isotopes"`
+	Volume   float64 `json:"volume"`
+}
+
+const (
+	fuelRaceSetting                 string = "fuel.race"
+	fuelJumpFuelConservationSetting string = "fuel.jumpFuelConservation"
+	fuelJumpFreightersSetting       string = "fuel.jumpFreighters"
+	// fuelHullSetting prefix of the hull saved for each range profile
+	fuelHullSetting string = "fuel.hull."
+	// isotopeVolume m3 of one isotope
+	isotopeVolume float64 = 0.03
+	// fuelSkillReduction how much less fuel each level of Jump Fuel Conservation or Jump Freighters burns
+	fuelSkillReduction float64 = 0.1
+	maxSkillLevel      int     = 5
+)
+
+// JumpHulls every hull fuel can be worked out for, grouped by range profile.
+var JumpHulls = []JumpHull{
+	{Name: "Black Ops", Profile: "Blops", IsotopesPerLightYear: 700},
+	{Name: "Titan", Profile: "Supers", IsotopesPerLightYear: 3000},
+	{Name: "Supercarrier", Profile: "Supers", IsotopesPerLightYear: 3000},
+	{Name: "Carrier", Profile: "Capitals", IsotopesPerLightYear: 3000},
+	{Name: "Dreadnought", Profile: "Capitals", IsotopesPerLightYear: 3000},
+	{Name: "Force Auxiliary", Profile: "Capitals", IsotopesPerLightYear: 3000},
+	{Name: "Jump Freighter", Profile: "Industry", IsotopesPerLightYear: 10000, JumpFreighter: true},
+}
+
+// FuelRaces the races in the order they are shown.
+var FuelRaces = []string{"Amarr", "Caldari", "Gallente", "Minmatar"}
+
+// RaceIsotopes the isotope each race's jump drives burn.
+var RaceIsotopes = map[string]string{
+	"Amarr":    "Helium Isotopes",
+	"Caldari":  "Nitrogen Isotopes",
+	"Gallente": "Oxygen Isotopes",
+	"Minmatar": "Hydrogen Isotopes",
+}
+
+// FindJumpHull matches a hull by name, case insensitive.
+func FindJumpHull(name string) (JumpHull, bool) {
+	for _, hull := range JumpHulls {
+		if strings.EqualFold(hull.Name, strings.TrimSpace(name)) {
+			return hull, true
+		}
+	}
+	return JumpHull{}, false
+}
+
+// FindFuelRace matches a race by name, case insensitive.
+func FindFuelRace(name string) (string, bool) {
+	for _, race := range FuelRaces {
+		if strings.EqualFold(race, strings.TrimSpace(name)) {
+			return race, true
+		}
+	}
+	return "", false
+}
+
+// ProfileHulls the hulls that jump with a range profile.
+func ProfileHulls(profile string) []JumpHull {
+	var hulls []JumpHull
+	for _, hull := range JumpHulls {
+		if hull.Profile == profile {
+			hulls = append(hulls, hull)
+		}
+	}
+	return hulls
+}
+
+// Hull the hull flown for a range profile.
+func (s FuelSettings) Hull(profile string) JumpHull {
+	if hull, found := FindJumpHull(s.Hulls[profile]); found && hull.Profile == profile {
+		return hull
+	}
+	if hulls := ProfileHulls(profile); len(hulls) > 0 {
+		return hulls[0]
+	}
+	return JumpHull{}
+}
+
+// GetFuelSettings the saved fuel settings, Amarr hulls and no skills until they are saved.
+func GetFuelSettings() FuelSettings {
+	settings := FuelSettings{Race: FuelRaces[0], Hulls: make(map[string]string)}
+	if race, found := FindFuelRace(GetSetting(fuelRaceSetting)); found {
+		settings.Race = race
+	}
+	settings.JumpFuelConservation, _ = strconv.Atoi(GetSetting(fuelJumpFuelConservationSetting))
+	settings.JumpFreighters, _ = strconv.Atoi(GetSetting(fuelJumpFreightersSetting))
+	for _, profile := range ShipRangeNames {
+		if hull := GetSetting(fuelHullSetting + profile); hull != "" {
+			settings.Hulls[profile] = hull
+		}
+	}
+	return settings
+}
+
+// SaveFuelSettings checks and saves the fuel settings.
+func SaveFuelSettings(settings FuelSettings) error {
+	race, found := FindFuelRace(settings.Race)
+	if !found {
+		return fmt.Errorf("unknown race %q, use %s", settings.Race, strings.Join(FuelRaces, ", "))
+	}
+	for _, level := range []int{settings.JumpFuelConservation, settings.JumpFreighters} {
+		if level < 0 || level > maxSkillLevel {
+			return fmt.Errorf("skill level %d has to be 0 to %d", level, maxSkillLevel)
+		}
+	}
+	for profile, name := range settings.Hulls {
+		hull, found := FindJumpHull(name)
+		if !found || hull.Profile != profile {
+			return fmt.Errorf("%q isn't a %s hull", name, profile)
+		}
+	}
+
+	if err := SaveSetting(fuelRaceSetting, race); err != nil {
+		return err
+	}
+	if err := SaveSetting(fuelJumpFuelConservationSetting, strconv.Itoa(settings.JumpFuelConservation)); err != nil {
+		return err
+	}
+	if err := SaveSetting(fuelJumpFreightersSetting, strconv.Itoa(settings.JumpFreighters)); err != nil {
+		return err
+	}
+	for profile, name := range settings.Hulls {
+		hull, _ := FindJumpHull(name)
+		if err := SaveSetting(fuelHullSetting+profile, hull.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// JumpFuel the isotopes a hull burns jumping a distance with the pilot's skills.
+func JumpFuel(hull JumpHull, settings FuelSettings, lightYears float64) FuelCost {
+	perLightYear := hull.IsotopesPerLightYear * (1 - fuelSkillReduction*float64(settings.JumpFuelConservation))
+	if hull.JumpFreighter {
+		perLightYear *= 1 - fuelSkillReduction*float64(settings.JumpFreighters)
+	}
+	isotopes := int(math.Ceil(perLightYear * lightYears))
+	return FuelCost{
+		Hull:     hull.Name,
+		Isotope:  RaceIsotopes[settings.Race],
+		Isotopes: isotopes,
+		Volume:   isotopesVolume(isotopes),
+	}
+}
+
+// Add the fuel of two jumps made by the same hull.
+func (c FuelCost) Add(other FuelCost) FuelCost {
+	c.Isotopes += other.Isotopes
+	c.Volume = isotopesVolume(c.Isotopes)
+	return c
+}
+
+// isotopesVolume the m3 a number of isotopes take up, rounded to the hundredth the game shows.
+func isotopesVolume(isotopes int) float64 {
+	return math.Round(float64(isotopes)*isotopeVolume*100) / 100
+}
+
+// addStagingFuel sets the fuel to jump to each staging with the hull flown for the profile.
+func addStagingFuel(stagings []StagingInRange, profile string, settings FuelSettings) {
+	hull := settings.Hull(profile)
+	for i := range stagings {
+		fuel := JumpFuel(hull, settings, stagings[i].LightYears)
+		stagings[i].Fuel = &fuel
+	}
+}
+
+// addRouteFuel sets the fuel of each jump in a route and the route's total.
+func addRouteFuel(plan *RoutePlan, hull JumpHull, settings FuelSettings) {
+	if plan.Jumps == 0 {
+		return
+	}
+	total := JumpFuel(hull, settings, 0)
+	for i, step := range plan.Steps {
+		if step.Type == RouteStepJump {
+			fuel := JumpFuel(hull, settings, step.LightYears)
+			plan.Steps[i].Fuel = &fuel
+			total = total.Add(fuel)
+		}
+	}
+	plan.Fuel = &total
+}
@@ -1,15 +1,25 @@
+//go:build gui
+
 package eveSolarSystems
 
 import (
 	"fmt"
 	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"github.com/sythe7448/Eve-Sonar/api"
+	"image/color"
+	"io"
 	"log"
 	"net/url"
+	"reflect"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -19,39 +29,94 @@ type ShipRangeSettings struct {
 
 // variables used locally throughout these functions
 var rangeSettings = ShipRangeSettings{}
+var activityFilter = ActivityFilter{SortBy: SortByName}
 var currentSolarSystemID string
+var currentSolarSystemLock sync.RWMutex
 var currentSystemText = widget.NewLabel("")
-var stagingInRangeText = widget.NewLabel("")
+var stagingTable *StagingTable
+var starMap *StarMap
+var starMapWindow fyne.Window
+var sovereigntyChangesText = widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+var sovereigntyChanges []SovereigntyChange
+
+var manualLocation = NewManualLocationSource()
+var followedCharacter = anyCharacter
+var followedCharacterLock sync.RWMutex
+var characterSelect = widget.NewSelect([]string{anyCharacter}, followCharacter)
+var intelMonitor *IntelMonitor
+var intelFeed []string
+
+// anyCharacter follow whichever tracked character moved last
+const anyCharacter = "Any character"
+
+// maxSovereigntyChanges how many sovereignty changes are kept on screen
+const maxSovereigntyChanges = 10
+
+// maxIntelFeed how many intel reports are kept in the feed
+const maxIntelFeed = 100
+
+// rangeAlertDelay range changes this close together are sent as one notification
+const rangeAlertDelay = time.Second
 
 // BuildContainer build/design the main container for the app using fyne.
 func BuildContainer(app fyne.App) *fyne.Container {
-	// Variables that are passed
-	currentSolarSystemID, _ = api.GetLocationId(api.Tokens.AccessToken, api.Character.CharacterID)
-	updateCurrentSystemName(currentSystemText, currentSolarSystemID)
+	// Tables need a running app so this one can't be made with the other widgets
+	stagingTable = NewStagingTable()
+	updateCurrentSystemName(currentSystemText, getCurrentSolarSystemID())
 
 	// Set each box
 	rangeSettingsBox := buildRangeSettingBox(app)
-	stagerSettingBox := buildStagerSettingsBox()
-	systemDataBox := container.NewVBox(
-		currentSystemText,
-		stagingInRangeText,
+	stagerSettingBox := buildStagerSettingsBox(app)
+	groupCheckBox := widget.NewCheck("Group by range", stagingTable.SetGrouped)
+	groupCheckBox.SetChecked(true)
+	systemDataBox := container.NewBorder(
+		container.NewVBox(currentSystemText, groupCheckBox),
+		container.NewVBox(sovereigntyChangesText, buildIntelBox(app)),
+		nil,
+		nil,
+		stagingTable.Table,
 	)
 
-	// Start a loop to update ranges every 10 seconds
+	// Every location source feeds the tracker, the UI only follows its merged stream
+	Tracker.AddSource(NewESILocationSource())
+	Tracker.AddSource(manualLocation)
+	go followLocations(Tracker.Subscribe())
+	StartLocationHistory()
+	go notifyRangeChanges(app, Events.Subscribe())
+	StartWebhooks()
+	StartSubscriptions()
+
+	// Keep the sovereignty cache fresh, ESI only updates the map about once an hour
 	go func() {
-		oldCurrentSolarSystemID := currentSolarSystemID
-		for range time.Tick(time.Second * 10) {
-			if len(api.Tokens.AccessToken) > 0 {
-				currentSolarSystemID, _ = api.GetLocationId(api.Tokens.AccessToken, api.Character.CharacterID)
-				if oldCurrentSolarSystemID != currentSolarSystemID {
-					updateCurrentSystemName(currentSystemText, currentSolarSystemID)
-					updateStagerText(rangeSettings, stagingInRangeText, currentSolarSystemID)
-					oldCurrentSolarSystemID = currentSolarSystemID
-				}
+		for {
+			changes, expires, err := RefreshSovereignty()
+			if err != nil {
+				log.Println("Error refreshing sovereignty:", err)
+				expires = time.Now().Add(time.Minute * 10)
+			} else {
+				updateSovereigntyChangesText(sovereigntyChangesText, changes)
+				updateStagingTable(rangeSettings, getCurrentSolarSystemID())
 			}
+			time.Sleep(time.Until(expires))
 		}
 	}()
 
+	// Regions come from ESI once and are kept in the DB after that
+	if !HasRegionIndex() {
+		go func() {
+			if _, err := EnsureRegionIndex(); err != nil {
+				log.Println("Error building region index:", err)
+				return
+			}
+			updateCurrentSystemName(currentSystemText, getCurrentSolarSystemID())
+			updateStagingTable(rangeSettings, getCurrentSolarSystemID())
+		}()
+	}
+
+	// Kills and jumps are cached by ESI separately so each gets its own refresh loop
+	go refreshActivity(RefreshSystemKills)
+	go refreshActivity(RefreshSystemJumps)
+
 	// Build the final lay out to return
 	hbox := container.New(
 		layout.NewGridLayout(3),
@@ -71,26 +136,38 @@ func buildRangeSettingBox(app fyne.App) *fyne.Container {
 	suggestionList := buildAutoComplete(systemInput)
 
 	manualSystemSubmit := widget.NewButton("Check Ranges", func() {
-		currentSolarSystemID = GetSystemByName(systemInput.Text).ID
-		updateCurrentSystemName(currentSystemText, currentSolarSystemID)
-		updateStagerText(rangeSettings, stagingInRangeText, currentSolarSystemID)
+		manualLocation.Submit(GetSystemByName(systemInput.Text).ID)
 	})
 	// Build check boxes for ranges
 	blopsCheckBox := widget.NewCheck("Blops Range", func(checked bool) {
 		rangeSettings.Blops = checked
-		updateStagerText(rangeSettings, stagingInRangeText, currentSolarSystemID)
+		updateStagingTable(rangeSettings, getCurrentSolarSystemID())
 	})
 	superCheckBox := widget.NewCheck("Super Range", func(checked bool) {
 		rangeSettings.Supers = checked
-		updateStagerText(rangeSettings, stagingInRangeText, currentSolarSystemID)
+		updateStagingTable(rangeSettings, getCurrentSolarSystemID())
 	})
 	capitalCheckBox := widget.NewCheck("Capital Range", func(checked bool) {
 		rangeSettings.Capitals = checked
-		updateStagerText(rangeSettings, stagingInRangeText, currentSolarSystemID)
+		updateStagingTable(rangeSettings, getCurrentSolarSystemID())
 	})
 	industryCheckBox := widget.NewCheck("Industry Range", func(checked bool) {
 		rangeSettings.Industry = checked
-		updateStagerText(rangeSettings, stagingInRangeText, currentSolarSystemID)
+		updateStagingTable(rangeSettings, getCurrentSolarSystemID())
+	})
+
+	// Activity filters
+	sortSelect := widget.NewSelect(ActivitySortOptions, func(sortBy string) {
+		activityFilter.SortBy = sortBy
+		stagingTable.ResetSort()
+		updateStagingTable(rangeSettings, getCurrentSolarSystemID())
+	})
+	sortSelect.SetSelected(activityFilter.SortBy)
+	minKillsInput := buildThresholdEntry("Min kills (ship + pod)", func(minKills int) {
+		activityFilter.MinKills = minKills
+	})
+	minJumpsInput := buildThresholdEntry("Min jumps", func(minJumps int) {
+		activityFilter.MinJumps = minJumps
 	})
 
 	// Login Button
@@ -110,13 +187,41 @@ func buildRangeSettingBox(app fyne.App) *fyne.Container {
 		systemInput,
 		suggestionList,
 		manualSystemSubmit,
+		widget.NewButtonWithIcon("Star map", theme.GridIcon(), func() {
+			showStarMap(app)
+		}),
+		widget.NewButtonWithIcon("Who can reach", theme.SearchIcon(), func() {
+			showReachWindow(app)
+		}),
+		widget.NewButton("Coverage", func() {
+			showCoverageWindow(app)
+		}),
+		widget.NewButton("Staging distances", func() {
+			showMatrixWindow(app)
+		}),
+		widget.NewButton("Titan and Blops bridges", func() {
+			showPortalWindow(app)
+		}),
+		widget.NewButton("Jump fuel", func() {
+			showFuelWindow(app)
+		}),
 		widget.NewLabel("Range options:"),
 		blopsCheckBox,
 		superCheckBox,
 		capitalCheckBox,
 		industryCheckBox,
+		widget.NewLabel("Sort and filter by last hour activity:"),
+		sortSelect,
+		minKillsInput,
+		minJumpsInput,
 		widget.NewLabel("Login to track location"),
 		loginButton,
+		buildChatLogSettingsBox(),
+		widget.NewLabel("Follow character:"),
+		characterSelect,
+		buildAlertsBox(),
+		buildLocalAPIBox(app),
+		buildLocationHistoryBox(app),
 		widget.NewButton("Quit", func() {
 			app.Quit()
 		}),
@@ -126,28 +231,686 @@ func buildRangeSettingBox(app fyne.App) *fyne.Container {
 
 }
 
-func buildStagerSettingsBox() *fyne.Container {
-	stagers := widget.NewMultiLineEntry()
+// buildChatLogSettingsBox settings to track location from the Local chat logs instead of ESI.
+func buildChatLogSettingsBox() *fyne.Container {
+	chatLogsPathInput := widget.NewEntry()
+	chatLogsPathInput.SetText(GetChatLogsPath())
+	chatLogsPathInput.SetPlaceHolder("EVE Chatlogs folder")
+
+	chatLogCheckBox := widget.NewCheck("Track location from Local chat logs", func(checked bool) {
+		if !checked {
+			Tracker.RemoveSource(SourceChatLog)
+			return
+		}
+		if err := SaveChatLogsPath(chatLogsPathInput.Text); err != nil {
+			log.Println("Error saving chat logs path:", err)
+		}
+		Tracker.AddSource(NewChatLogWatcher(chatLogsPathInput.Text))
+	})
+
+	return container.NewVBox(
+		widget.NewLabel("Or track location without ESI"),
+		chatLogsPathInput,
+		chatLogCheckBox,
+	)
+}
+
+// buildIntelBox settings for intel channel monitoring and a scrolling feed of parsed reports.
+func buildIntelBox(app fyne.App) *fyne.Container {
+	channelsInput := widget.NewEntry()
+	channelsInput.SetText(strings.Join(GetIntelChannels(), ", "))
+	channelsInput.SetPlaceHolder("Intel channels, comma separated")
+
+	alertRange := "Capitals"
+	rangeSelect := widget.NewSelect(ShipRangeNames, func(rangeName string) {
+		alertRange = rangeName
+	})
+	rangeSelect.SetSelected(alertRange)
+
+	gateJumpsInput := widget.NewEntry()
+	gateJumpsInput.SetPlaceHolder("Off")
+	if gateJumps := GetIntelGateJumps(); gateJumps != IntelGateJumpsOff {
+		gateJumpsInput.SetText(strconv.Itoa(gateJumps))
+	}
+	gateJumpsInput.OnSubmitted = func(text string) {
+		gateJumps := IntelGateJumpsOff
+		var err error
+		if text = strings.TrimSpace(text); text != "" {
+			gateJumps, err = strconv.Atoi(text)
+			if err == nil && gateJumps < 0 {
+				err = fmt.Errorf("can't be negative")
+			}
+		}
+		if err == nil {
+			err = SaveIntelGateJumps(gateJumps)
+		}
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("gate jumps %q: %w", text, err), mainWindow(app))
+			gateJumpsInput.SetText("")
+			if saved := GetIntelGateJumps(); saved != IntelGateJumpsOff {
+				gateJumpsInput.SetText(strconv.Itoa(saved))
+			}
+		}
+	}
+
+	lastAlertText := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	feedText := widget.NewLabel("")
+	feedContainer := container.NewScroll(feedText)
+	feedContainer.SetMinSize(fyne.NewSize(100, 200))
+
+	monitorCheckBox := widget.NewCheck("Monitor intel channels", func(checked bool) {
+		if intelMonitor != nil {
+			intelMonitor.Stop()
+			intelMonitor = nil
+		}
+		if !checked {
+			return
+		}
+		channels := splitCommaList(channelsInput.Text)
+		if err := SaveIntelChannels(channels); err != nil {
+			log.Println("Error saving intel channels:", err)
+		}
+		intelMonitor = NewIntelMonitor(GetChatLogsPath(), channels, func(report IntelReport) {
+			entry := fmt.Sprintf("%s %s %s: %s", report.Time.Format("15:04:05"), report.Channel, report.Reporter, report.Message)
+			alerts := CheckIntelReport(report, ShipRanges[alertRange], GetIntelGateJumps(), getCurrentSolarSystemID())
+			if len(alerts) > 0 {
+				alert := fmt.Sprintf("%s is %s from %s", alerts[0].System.Name, FormatDistance(alerts[0].LightYears, alerts[0].GateJumps), alerts[0].Near)
+				entry = "ALERT " + entry + "\n    " + alert
+				lastAlertText.SetText("Intel alert: " + alert)
+				app.SendNotification(fyne.NewNotification("Intel alert", alert+"\n"+report.Message))
+			}
+			intelFeed = append([]string{entry}, intelFeed...)
+			if len(intelFeed) > maxIntelFeed {
+				intelFeed = intelFeed[:maxIntelFeed]
+			}
+			feedText.SetText(strings.Join(intelFeed, "\n"))
+		})
+		intelMonitor.Start()
+	})
+
+	return container.NewVBox(
+		widget.NewLabel("Intel channels"),
+		channelsInput,
+		widget.NewLabel("Alert when reported systems are in range:"),
+		rangeSelect,
+		container.NewBorder(nil, nil, widget.NewLabel("Or within gate jumps"), nil, gateJumpsInput),
+		monitorCheckBox,
+		lastAlertText,
+		feedContainer,
+	)
+}
+
+// buildAlertsBox settings for the notification and sound played when stagings enter or leave range.
+func buildAlertsBox() *fyne.Container {
+	desktopAlertsCheckBox := widget.NewCheck("Notify when stagings enter or leave range", func(checked bool) {
+		if err := SaveDesktopAlertsEnabled(checked); err != nil {
+			log.Println("Error saving desktop alerts setting:", err)
+		}
+	})
+	desktopAlertsCheckBox.SetChecked(AreDesktopAlertsEnabled())
 
-	stagers.SetText(ConvertStagingSystemsToSting())
-	stagers.SetPlaceHolder("system:owner")
-	suggestionList := buildAutoComplete(stagers)
-	stagerContainer := container.NewScroll(stagers)
-	stagerContainer.SetMinSize(fyne.NewSize(100, 350))
-	saveStagers := widget.NewButton("Submit", func() {
-		ParseAndSaveStagingSystems(stagers.Text)
-		updateStagerText(rangeSettings, stagingInRangeText, currentSolarSystemID)
+	soundInput := widget.NewEntry()
+	soundInput.SetText(GetAlertSound())
+	soundInput.SetPlaceHolder("Alert sound file (.wav), blank for none")
+	statusText := widget.NewLabel("")
+	saveSoundButton := widget.NewButton("Save sound", func() {
+		if err := SaveAlertSound(soundInput.Text); err != nil {
+			statusText.SetText(err.Error())
+			return
+		}
+		statusText.SetText("")
+	})
+	testSoundButton := widget.NewButton("Play", func() {
+		go func(path string) {
+			if err := PlayAlertSound(path); err != nil {
+				statusText.SetText(err.Error())
+			}
+		}(strings.TrimSpace(soundInput.Text))
+	})
+
+	return container.NewVBox(
+		desktopAlertsCheckBox,
+		soundInput,
+		container.NewGridWithColumns(2, saveSoundButton, testSoundButton),
+		statusText,
+	)
+}
+
+// buildLocalAPIBox turns the local HTTP API for bots and other tools on and off, and sets the token changes need.
+func buildLocalAPIBox(app fyne.App) *fyne.Container {
+	addressInput := widget.NewEntry()
+	addressInput.SetText(GetLocalAPIAddress())
+
+	apiCheckBox := widget.NewCheck("Enable local API", func(checked bool) {
+		if !checked {
+			if err := StopLocalAPI(); err != nil {
+				log.Println("Error stopping local API:", err)
+			}
+			return
+		}
+		if err := StartLocalAPI(addressInput.Text); err != nil {
+			log.Println("Error starting local API:", err)
+		}
+	})
+	apiCheckBox.SetChecked(IsLocalAPIEnabled())
+
+	tokenText := widget.NewLabel("")
+	updateTokenText := func() {
+		if GetLocalAPIToken() == "" {
+			tokenText.SetText("Changes need no token")
+			return
+		}
+		tokenText.SetText("Changes need the token")
+	}
+	updateTokenText()
+	newTokenButton := widget.NewButton("New token", func() {
+		token, err := NewLocalAPIToken()
+		if err != nil {
+			dialog.ShowError(err, mainWindow(app))
+			return
+		}
+		mainWindow(app).Clipboard().SetContent(token)
+		updateTokenText()
+		dialog.ShowInformation("Local API token", "Copied the new token, send it as Authorization: Bearer <token>", mainWindow(app))
+	})
+	clearTokenButton := widget.NewButton("No token", func() {
+		if err := ClearLocalAPIToken(); err != nil {
+			dialog.ShowError(err, mainWindow(app))
+			return
+		}
+		updateTokenText()
+	})
+
+	return container.NewVBox(
+		widget.NewLabel("Local API for bots and tools"),
+		addressInput,
+		apiCheckBox,
+		tokenText,
+		container.NewGridWithColumns(2, newTokenButton, clearTokenButton),
+	)
+}
+
+// buildLocationHistoryBox turns location history on and off and sets how long it is kept.
+func buildLocationHistoryBox(app fyne.App) *fyne.Container {
+	historyCheckBox := widget.NewCheck("Keep location history", func(checked bool) {
+		if err := SaveLocationHistoryEnabled(checked); err != nil {
+			log.Println("Error saving location history setting:", err)
+		}
+	})
+	historyCheckBox.SetChecked(IsLocationHistoryEnabled())
+
+	daysInput := widget.NewEntry()
+	daysInput.SetText(strconv.Itoa(GetLocationHistoryDays()))
+	daysInput.OnSubmitted = func(text string) {
+		days, err := strconv.Atoi(strings.TrimSpace(text))
+		if err == nil {
+			err = SaveLocationHistoryDays(days)
+		}
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("days %q: %w", text, err), mainWindow(app))
+			daysInput.SetText(strconv.Itoa(GetLocationHistoryDays()))
+		}
+	}
+
+	return container.NewVBox(
+		historyCheckBox,
+		container.NewBorder(nil, nil, widget.NewLabel("Days kept"), nil, daysInput),
+		widget.NewButton("Movement timeline", func() {
+			showTimelineWindow(app)
+		}),
+	)
+}
+
+func buildStagerSettingsBox(app fyne.App) *fyne.Container {
+	selectedList := DefaultStagingList
+	editor := NewStagingEditor()
+	statusText := widget.NewLabel("")
+	undoButton := widget.NewButtonWithIcon("Undo", theme.ContentUndoIcon(), editor.Undo)
+	// The buttons that change the list, disabled while it is subscribed
+	var editButtons []fyne.Disableable
+	editor.OnChanged = func() {
+		undoButton.Enable()
+		if !editor.CanUndo() {
+			undoButton.Disable()
+		}
+		for _, button := range editButtons {
+			if editor.ReadOnly() {
+				button.Disable()
+			} else {
+				button.Enable()
+			}
+		}
+		switch count := editor.ErrorCount(); {
+		case editor.ReadOnly():
+			statusText.SetText("Read only, this list is synced from a shared list")
+		case count > 0:
+			statusText.SetText(fmt.Sprintf("%d rows need fixing before saving", count))
+		case editor.Dirty():
+			statusText.SetText("Unsaved changes")
+		default:
+			statusText.SetText("")
+		}
+	}
+	addRowButton := widget.NewButtonWithIcon("Add row", theme.ContentAddIcon(), editor.AddRow)
+	pasteButton := widget.NewButtonWithIcon("Paste text", theme.ContentPasteIcon(), func() {
+		showPasteStagingsDialog(app, editor)
+	})
+	saveStagers := widget.NewButtonWithIcon("Save", theme.DocumentSaveIcon(), func() {
+		if err := editor.Save(); err != nil {
+			statusText.SetText(err.Error())
+			return
+		}
+		updateStagingTable(rangeSettings, getCurrentSolarSystemID())
+	})
+	editorContainer := container.New(layout.NewGridWrapLayout(fyne.NewSize(420, 350)), editor.List)
+	importButton := widget.NewButtonWithIcon("Import", theme.FolderOpenIcon(), func() {
+		showImportStagingsDialog(app, editor, selectedList)
+	})
+	exportButton := widget.NewButtonWithIcon("Export", theme.DocumentSaveIcon(), func() {
+		showExportStagingsDialog(app, selectedList)
+	})
+	editButtons = []fyne.Disableable{addRowButton, pasteButton, saveStagers, importButton}
+	subscriptionBox, updateSubscriptionBox := buildSubscriptionBox(app, func() string {
+		return selectedList
+	}, func() {
+		editor.Load(selectedList)
+		updateStagingTable(rangeSettings, getCurrentSolarSystemID())
+	})
+	editor.Load(selectedList)
+
+	// Staging lists, each list is edited on its own
+	muteCheckBox := widget.NewCheck("Mute alerts for this list", nil)
+	var listSelect *widget.Select
+	listSelect = widget.NewSelect(GetStagingListNames(), func(list string) {
+		if list == selectedList && editor.Dirty() {
+			return
+		}
+		switchList := func() {
+			selectedList = list
+			editor.Load(list)
+			muteCheckBox.SetChecked(IsListMuted(list))
+			updateSubscriptionBox()
+		}
+		if !editor.Dirty() {
+			switchList()
+			return
+		}
+		// Put the old list back until the unsaved changes are discarded
+		previousList := selectedList
+		listSelect.SetSelected(previousList)
+		dialog.ShowConfirm("Unsaved changes", fmt.Sprintf("Discard the unsaved changes to %s?", previousList), func(discard bool) {
+			if discard {
+				editor.Load(previousList)
+				listSelect.SetSelected(list)
+			}
+		}, mainWindow(app))
+	})
+	listSelect.SetSelected(selectedList)
+	muteCheckBox.OnChanged = func(checked bool) {
+		if err := SetListMuted(selectedList, checked); err != nil {
+			log.Println("Error saving muted lists:", err)
+		}
+	}
+	newListInput := widget.NewEntry()
+	newListInput.SetPlaceHolder("New list name")
+	newListButton := widget.NewButton("Add list", func() {
+		list, err := CreateStagingList(newListInput.Text)
+		if err != nil {
+			log.Println("Error adding staging list:", err)
+			return
+		}
+		newListInput.SetText("")
+		listSelect.Options = GetStagingListNames()
+		listSelect.SetSelected(list)
+	})
+	deleteListButton := widget.NewButton("Delete list", func() {
+		if err := DeleteStagingList(selectedList); err != nil {
+			log.Println("Error deleting staging list:", err)
+			return
+		}
+		listSelect.Options = GetStagingListNames()
+		listSelect.SetSelected(DefaultStagingList)
+		updateStagingTable(rangeSettings, getCurrentSolarSystemID())
 	})
 
 	stagerSettingBox := container.NewVBox(
-		widget.NewLabel("Staging Systems\n system:owner \n new line for new entry"),
-		suggestionList,
-		stagerContainer,
-		saveStagers,
+		widget.NewLabel("Staging list:"),
+		listSelect,
+		container.NewGridWithColumns(3, newListInput, newListButton, deleteListButton),
+		muteCheckBox,
+		widget.NewLabel("Staging Systems"),
+		editorContainer,
+		container.NewGridWithColumns(4, addRowButton, undoButton, pasteButton, saveStagers),
+		container.NewGridWithColumns(2, importButton, exportButton),
+		statusText,
+		subscriptionBox,
+		buildWebhooksBox(),
 	)
+
+	// Lists synced in the background are reloaded when they are the one shown
+	go func(events <-chan Event) {
+		for event := range events {
+			if event.Type != EventStagingListSynced {
+				continue
+			}
+			if event.List == selectedList && !editor.Dirty() {
+				editor.Load(selectedList)
+				updateSubscriptionBox()
+			}
+			updateStagingTable(rangeSettings, getCurrentSolarSystemID())
+		}
+	}(Events.Subscribe())
+
 	return stagerSettingBox
 }
 
+// buildSubscriptionBox subscribes the selected list to a shared file or URL and shows what its syncs changed.
+// The returned func updates the box after the selected list changes.
+func buildSubscriptionBox(app fyne.App, selectedList func() string, reload func()) (*fyne.Container, func()) {
+	sourceInput := widget.NewEntry()
+	sourceInput.SetPlaceHolder("https:// URL or file path")
+	refreshInput := widget.NewEntry()
+	refreshInput.SetPlaceHolder(fmt.Sprintf("Refresh every %d minutes", DefaultRefreshMinutes))
+	statusText := widget.NewLabel("")
+	statusText.Wrapping = fyne.TextWrapWord
+	changesText := widget.NewLabel("")
+	changesText.Wrapping = fyne.TextWrapWord
+	subscribeButton := widget.NewButton("Subscribe", nil)
+	unsubscribeButton := widget.NewButton("Unsubscribe", nil)
+	syncButton := widget.NewButtonWithIcon("Sync now", theme.ViewRefreshIcon(), nil)
+
+	update := func() {
+		list := selectedList()
+		subscription := GetSubscription(list)
+		if subscription.List == "" {
+			for _, object := range []fyne.Disableable{sourceInput, refreshInput, subscribeButton} {
+				object.Enable()
+			}
+			unsubscribeButton.Disable()
+			syncButton.Disable()
+			statusText.SetText("Subscribing replaces the list with the shared one and keeps it in sync")
+			if list == DefaultStagingList {
+				subscribeButton.Disable()
+				statusText.SetText(fmt.Sprintf("The %s list can't be subscribed, add a list first", DefaultStagingList))
+			}
+			changesText.SetText("")
+			return
+		}
+
+		sourceInput.SetText(subscription.Source)
+		refreshInput.SetText(strconv.Itoa(subscription.RefreshMinutes))
+		for _, object := range []fyne.Disableable{sourceInput, refreshInput, subscribeButton} {
+			object.Disable()
+		}
+		unsubscribeButton.Enable()
+		syncButton.Enable()
+		status := fmt.Sprintf("Synced every %d minutes, last synced %s", subscription.RefreshMinutes, subscription.LastSync.Format("Jan 2 15:04"))
+		if subscription.LastError != "" {
+			status += "\nLast sync failed: " + subscription.LastError
+		}
+		statusText.SetText(status)
+		changesText.SetText(subscriptionChangeLog(subscription.Changes))
+	}
+
+	subscribeButton.OnTapped = func() {
+		refreshMinutes := 0
+		if text := strings.TrimSpace(refreshInput.Text); text != "" {
+			minutes, err := strconv.Atoi(text)
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("refresh %q isn't a number of minutes", text), mainWindow(app))
+				return
+			}
+			refreshMinutes = minutes
+		}
+		subscribeButton.Disable()
+		statusText.SetText("Syncing...")
+		go func() {
+			_, _, err := Subscribe(selectedList(), sourceInput.Text, "", refreshMinutes)
+			if err != nil {
+				dialog.ShowError(err, mainWindow(app))
+			}
+			reload()
+			update()
+		}()
+	}
+	unsubscribeButton.OnTapped = func() {
+		if err := Unsubscribe(selectedList()); err != nil {
+			dialog.ShowError(err, mainWindow(app))
+		}
+		sourceInput.SetText("")
+		refreshInput.SetText("")
+		reload()
+		update()
+	}
+	syncButton.OnTapped = func() {
+		syncButton.Disable()
+		go func() {
+			change, err := SyncSubscription(selectedList())
+			if err != nil {
+				dialog.ShowError(err, mainWindow(app))
+			}
+			reload()
+			update()
+			if err == nil {
+				statusText.SetText(statusText.Text + "\nThis sync: " + change.Summary())
+			}
+		}()
+	}
+	update()
+
+	return container.NewVBox(
+		widget.NewLabel("Shared list"),
+		sourceInput,
+		container.NewGridWithColumns(4, refreshInput, subscribeButton, unsubscribeButton, syncButton),
+		statusText,
+		changesText,
+	), update
+}
+
+// subscriptionChangeLog one line per sync that changed a subscribed list, newest first.
+func subscriptionChangeLog(changes []SubscriptionChange) string {
+	if len(changes) == 0 {
+		return "No changes synced yet"
+	}
+	var lines []string
+	for _, change := range changes {
+		lines = append(lines, change.Time.Format("Jan 2 15:04")+" "+change.Summary())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// showPasteStagingsDialog adds pasted system:owner lines to the editor and lists the lines that were rejected.
+func showPasteStagingsDialog(app fyne.App, editor *StagingEditor) {
+	pasteInput := widget.NewMultiLineEntry()
+	pasteInput.SetPlaceHolder("system:owner\nOne staging per line")
+	window := mainWindow(app)
+	pasteDialog := dialog.NewCustomConfirm("Paste stagings", "Add", "Cancel", container.NewScroll(pasteInput), func(add bool) {
+		if !add {
+			return
+		}
+		rejected := editor.Paste(pasteInput.Text)
+		if len(rejected) == 0 {
+			return
+		}
+		showRejectedLines(window, "", rejected)
+	}, window)
+	pasteDialog.Resize(fyne.NewSize(500, 400))
+	pasteDialog.Show()
+}
+
+// showImportStagingsDialog imports a JSON, CSV, YAML or plain names file into a list, merging or replacing it.
+func showImportStagingsDialog(app fyne.App, editor *StagingEditor, list string) {
+	window := mainWindow(app)
+	dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil || reader == nil {
+			return
+		}
+		defer reader.Close()
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+		entries, rejected, err := ParseStagingFile(data, StagingFormatFromFilename(reader.URI().Name()))
+		if err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+
+		modeRadio := widget.NewRadioGroup([]string{"Merge into the list", "Replace the list"}, nil)
+		modeRadio.SetSelected("Merge into the list")
+		message := fmt.Sprintf("%d stagings to import into %s, %d lines rejected.", len(entries), list, len(rejected))
+		if editor.Dirty() {
+			message += "\nUnsaved edits to the list will be lost."
+		}
+		dialog.ShowCustomConfirm("Import stagings", "Import", "Cancel", container.NewVBox(widget.NewLabel(message), modeRadio), func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			result, err := ImportStagings(list, entries, modeRadio.Selected == "Replace the list")
+			if err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+			editor.Load(list)
+			updateStagingTable(rangeSettings, getCurrentSolarSystemID())
+			summary := fmt.Sprintf("Added %d, updated %d, unchanged %d, removed %d.\n", len(result.Added), len(result.Updated), len(result.Unchanged), len(result.Removed))
+			showRejectedLines(window, summary, rejected)
+		}, window)
+	}, window)
+}
+
+// showExportStagingsDialog saves a list to a file, the format comes from the file extension.
+func showExportStagingsDialog(app fyne.App, list string) {
+	window := mainWindow(app)
+	saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil || writer == nil {
+			return
+		}
+		defer writer.Close()
+		data, err := ExportStagingList(list, StagingFormatFromFilename(writer.URI().Name()))
+		if err == nil {
+			_, err = writer.Write(data)
+		}
+		if err != nil {
+			dialog.ShowError(err, window)
+		}
+	}, window)
+	saveDialog.SetFileName(list + ".json")
+	saveDialog.Show()
+}
+
+// showRejectedLines shows a summary and the lines that couldn't be used, nothing when there is neither.
+func showRejectedLines(window fyne.Window, summary string, rejected []RejectedLine) {
+	if summary == "" && len(rejected) == 0 {
+		return
+	}
+	text := summary
+	if len(rejected) > 0 {
+		text += fmt.Sprintf("%d lines were not added:\n", len(rejected))
+	}
+	for _, line := range rejected {
+		text += fmt.Sprintf("Line %d \"%s\": %s\n", line.Line, line.Text, line.Reason)
+	}
+	rejectedText := widget.NewLabel(text)
+	rejectedText.Wrapping = fyne.TextWrapWord
+	rejectedDialog := dialog.NewCustom("Import", "OK", container.NewScroll(rejectedText), window)
+	rejectedDialog.Resize(fyne.NewSize(500, 300))
+	rejectedDialog.Show()
+}
+
+// mainWindow the app window, dialogs are shown over it.
+func mainWindow(app fyne.App) fyne.Window {
+	return app.Driver().AllWindows()[0]
+}
+
+// buildWebhooksBox adds, tests and removes the webhooks fired when stagings enter or leave range.
+func buildWebhooksBox() *fyne.Container {
+	webhooksText := widget.NewLabel("")
+	updateWebhooksText := func() {
+		text := ""
+		for _, webhook := range GetWebhooks() {
+			text += fmt.Sprintf("%s (%s)", webhook.Name, webhook.Format)
+			for _, filter := range [][]string{webhook.Lists, webhook.Profiles, webhook.Characters} {
+				if len(filter) > 0 {
+					text += " " + strings.Join(filter, ",")
+				}
+			}
+			text += "\n"
+		}
+		if text == "" {
+			text = "No webhooks\n"
+		}
+		webhooksText.SetText(text)
+	}
+	updateWebhooksText()
+
+	nameInput := widget.NewEntry()
+	nameInput.SetPlaceHolder("Webhook name")
+	urlInput := widget.NewEntry()
+	urlInput.SetPlaceHolder("Webhook URL")
+	formatSelect := widget.NewSelect(WebhookFormats, nil)
+	formatSelect.SetSelected(WebhookDiscord)
+	listsInput := widget.NewEntry()
+	listsInput.SetPlaceHolder("Lists, comma separated, blank for all")
+	profilesInput := widget.NewEntry()
+	profilesInput.SetPlaceHolder("Range profiles, comma separated, blank for all")
+	charactersInput := widget.NewEntry()
+	charactersInput.SetPlaceHolder("Characters, comma separated, blank for all")
+	statusText := widget.NewLabel("")
+
+	readWebhook := func() Webhook {
+		return Webhook{
+			Name:       nameInput.Text,
+			URL:        urlInput.Text,
+			Format:     formatSelect.Selected,
+			Lists:      splitCommaList(listsInput.Text),
+			Profiles:   splitCommaList(profilesInput.Text),
+			Characters: splitCommaList(charactersInput.Text),
+		}
+	}
+	saveButton := widget.NewButton("Save webhook", func() {
+		webhook, err := SaveWebhook(readWebhook())
+		if err != nil {
+			statusText.SetText(err.Error())
+			return
+		}
+		statusText.SetText("Saved " + webhook.Name)
+		updateWebhooksText()
+	})
+	testButton := widget.NewButton("Test", func() {
+		statusText.SetText("Sending test...")
+		go func(webhook Webhook) {
+			if err := TestWebhook(webhook); err != nil {
+				statusText.SetText("Test failed: " + err.Error())
+				return
+			}
+			statusText.SetText("Test sent")
+		}(readWebhook())
+	})
+	removeButton := widget.NewButton("Remove", func() {
+		if err := RemoveWebhook(nameInput.Text); err != nil {
+			statusText.SetText(err.Error())
+			return
+		}
+		statusText.SetText("Removed " + nameInput.Text)
+		updateWebhooksText()
+	})
+
+	return container.NewVBox(
+		widget.NewLabel("Webhooks on stagings entering or leaving range:"),
+		webhooksText,
+		nameInput,
+		urlInput,
+		formatSelect,
+		listsInput,
+		profilesInput,
+		charactersInput,
+		container.NewGridWithColumns(3, saveButton, testButton, removeButton),
+		statusText,
+	)
+}
+
 func buildAutoComplete(input *widget.Entry) *fyne.Container {
 	suggestionList := container.NewVBox()
 	input.OnChanged = func(text string) {
@@ -196,17 +959,133 @@ func setText(button *widget.Button, input *widget.Entry, oldText string, suggest
 }
 
 func getSystemSuggestions(prefix string) []string {
-	// Sample suggestions for demonstration
-	options := GetAllSystems()
 	var suggestions []string
+	for _, solarSystem := range SearchSystems(prefix) {
+		suggestions = append(suggestions, solarSystem.Name)
+	}
+
+	return suggestions
+}
 
-	for _, option := range options {
-		if strings.HasPrefix(strings.ToLower(option), strings.ToLower(prefix)) {
-			suggestions = append(suggestions, option)
+// followLocations moves the current system for each location event of the followed character.
+// Manual input always moves it.
+func followLocations(locations <-chan LocationEvent) {
+	for event := range locations {
+		if !containsString(characterSelect.Options, event.Character) {
+			characterSelect.Options = append(characterSelect.Options, event.Character)
+			characterSelect.Refresh()
+		}
+		if event.Source == SourceManual || isFollowed(event.Character) {
+			setCurrentSolarSystem(event.SolarSystemID)
 		}
 	}
+}
 
-	return suggestions
+// followCharacter switches the followed character and jumps to where they were last seen.
+func followCharacter(character string) {
+	followedCharacterLock.Lock()
+	followedCharacter = character
+	followedCharacterLock.Unlock()
+
+	if event, found := Tracker.Locations()[character]; found {
+		setCurrentSolarSystem(event.SolarSystemID)
+	}
+}
+
+// isFollowed true when the character is the followed one or any character is followed.
+func isFollowed(character string) bool {
+	followedCharacterLock.RLock()
+	defer followedCharacterLock.RUnlock()
+
+	return followedCharacter == anyCharacter || followedCharacter == character
+}
+
+// notifyRangeChanges sends a desktop notification and plays the alert sound when stagings in unmuted lists
+// enter or leave a ticked range of the followed character.
+func notifyRangeChanges(app fyne.App, events <-chan Event) {
+	var pending []Event
+	var flush <-chan time.Time
+	for {
+		select {
+		case event, open := <-events:
+			if !open {
+				return
+			}
+			if !isRangeAlert(event) {
+				continue
+			}
+			pending = append(pending, event)
+			if flush == nil {
+				flush = time.After(rangeAlertDelay)
+			}
+		case <-flush:
+			sendRangeAlert(app, pending)
+			pending, flush = nil, nil
+		}
+	}
+}
+
+func isRangeAlert(event Event) bool {
+	if event.Type != EventStagingEnteredRange && event.Type != EventStagingLeftRange {
+		return false
+	}
+	if event.Character != SourceManual && !isFollowed(event.Character) {
+		return false
+	}
+	return isRangeSelected(rangeSettings, event.Profile) && !IsListMuted(event.Staging.List)
+}
+
+func sendRangeAlert(app fyne.App, events []Event) {
+	entered := 0
+	var lines []string
+	for _, event := range events {
+		if event.Type == EventStagingEnteredRange {
+			entered++
+		}
+		lines = append(lines, RangeChangeMessage(event))
+	}
+
+	title := fmt.Sprintf("%d stagings came into range", entered)
+	switch {
+	case len(events) == 1 && entered == 1:
+		title = "Staging in range"
+	case len(events) == 1:
+		title = "Staging out of range"
+	case entered != len(events):
+		title = fmt.Sprintf("%d stagings came into range, %d went out of range", entered, len(events)-entered)
+	}
+
+	if AreDesktopAlertsEnabled() {
+		app.SendNotification(fyne.NewNotification(title, strings.Join(lines, "\n")))
+	}
+	go func(path string) {
+		if err := PlayAlertSound(path); err != nil {
+			log.Println("Error playing alert sound:", err)
+		}
+	}(GetAlertSound())
+}
+
+// isRangeSelected true when the range option is ticked.
+func isRangeSelected(shipRangesSettings ShipRangeSettings, rangeName string) bool {
+	field := reflect.ValueOf(shipRangesSettings).FieldByName(rangeName)
+	return field.IsValid() && field.Bool()
+}
+
+// setCurrentSolarSystem moves the current system and refreshes what is in range.
+func setCurrentSolarSystem(solarSystemID string) {
+	currentSolarSystemLock.Lock()
+	currentSolarSystemID = solarSystemID
+	currentSolarSystemLock.Unlock()
+
+	updateCurrentSystemName(currentSystemText, solarSystemID)
+	updateStagingTable(rangeSettings, solarSystemID)
+}
+
+func getCurrentSolarSystemID() string {
+	currentSolarSystemLock.RLock()
+	defer currentSolarSystemLock.RUnlock()
+
+	return currentSolarSystemID
 }
 
 func updateCurrentSystemName(currentSystemText *widget.Label, currentSolarSystemID string) {
@@ -214,16 +1093,630 @@ func updateCurrentSystemName(currentSystemText *widget.Label, currentSolarSystem
 		currentSystemText.SetText(fmt.Sprintf("Current System: No System Found\n If this is a manual input check spelling"))
 		return
 	}
-	currentSolarSystemName := GetSystemByID(currentSolarSystemID).Name
-	currentSystemText.SetText(fmt.Sprintf("Current System: %s", currentSolarSystemName))
+	currentSolarSystem := GetSystemByID(currentSolarSystemID)
+	currentSystemText.SetText(fmt.Sprintf("Current System: %s %.1f %s", currentSolarSystem.Name, DisplaySecurity(currentSolarSystem.Sec), GetSystemRegion(currentSolarSystemID)))
 }
 
-func updateStagerText(rangeSettings ShipRangeSettings, rangeText *widget.Label, currentSolarSystemID string) {
+// updateStagingTable shows the stagings in each ticked range of the current system.
+func updateStagingTable(rangeSettings ShipRangeSettings, currentSolarSystemID string) {
+	updateStarMap()
 	if len(currentSolarSystemID) == 0 {
 		return
 	}
 	currentSolarSystem := GetSystemByID(currentSolarSystemID)
-	rangeText.SetText(GetStagingSystemsBySelectedRangeText(rangeSettings, currentSolarSystem))
+	fuelSettings := GetFuelSettings()
+	var results []RangeResult
+	for _, rangeName := range ShipRangeNames {
+		if isRangeSelected(rangeSettings, rangeName) {
+			stagings := GetStagingsInRange(currentSolarSystem.Coordinates, ShipRanges[rangeName])
+			addStagingFuel(stagings, rangeName, fuelSettings)
+			results = append(results, RangeResult{Profile: rangeName, Stagings: FilterAndSortStagings(stagings, activityFilter)})
+		}
+	}
+	stagingTable.SetResults(results)
+}
+
+// showStarMap opens the star map window, or brings it to the front when it is already open.
+func showStarMap(app fyne.App) {
+	if starMapWindow != nil {
+		starMapWindow.RequestFocus()
+		return
+	}
+
+	starMap = NewStarMap()
+	// Clicking a system checks its ranges the same as typing it in
+	starMap.OnSystemTapped = func(solarSystem SolarSystem) {
+		manualLocation.Submit(solarSystem.ID)
+	}
+	updateStarMap()
+
+	toolbar := container.NewHBox(
+		widget.NewButtonWithIcon("", theme.ZoomInIcon(), func() { starMap.Zoom(starMapZoomStep) }),
+		widget.NewButtonWithIcon("", theme.ZoomOutIcon(), func() { starMap.Zoom(1 / starMapZoomStep) }),
+		widget.NewButton("Current system", starMap.CenterOnCurrent),
+		widget.NewButton("Show all", starMap.ShowAll),
+		widget.NewButton("Jump bridges", func() { showJumpBridgesDialog(starMapWindow) }),
+		widget.NewLabel("Drag to pan, scroll to zoom, click a system to check its ranges"),
+	)
+
+	starMapWindow = app.NewWindow("Eve Sonar star map")
+	starMapWindow.SetContent(container.NewBorder(toolbar, nil, nil, nil, starMap))
+	starMapWindow.Resize(fyne.NewSize(900, 700))
+	starMapWindow.SetOnClosed(func() {
+		starMapWindow, starMap = nil, nil
+	})
+	starMapWindow.Show()
+}
+
+// showReachWindow lists the systems a fleet could jump into every ticked staging from.
+func showReachWindow(app fyne.App) {
+	var systems []SystemInReach
+	resultsList := widget.NewList(
+		func() int { return len(systems) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			system := systems[id]
+			text := fmt.Sprintf("%s %.1f  %.2f LY", system.System.Name, DisplaySecurity(system.System.Sec), system.LightYears)
+			for _, detail := range []string{system.Region, system.Sov} {
+				if detail != "" {
+					text += "  " + detail
+				}
+			}
+			item.(*widget.Label).SetText(text)
+		},
+	)
+	statusText := widget.NewLabel("Tick one staging to see where it can be jumped into from, or several to see where all of them can be reached from.")
+	statusText.Wrapping = fyne.TextWrapWord
+
+	stagingChecks := widget.NewCheckGroup(GetStagingSystemNames(), nil)
+	profile := "Capitals"
+	profileSelect := widget.NewSelect(ShipRangeNames, func(rangeName string) {
+		profile = rangeName
+	})
+	profileSelect.SetSelected(profile)
+	regionSelect := widget.NewSelect(append([]string{"Any region"}, GetRegionNames()...), nil)
+	regionSelect.SetSelected("Any region")
+	sovInput := widget.NewEntry()
+	sovInput.SetPlaceHolder("Sov holder")
+
+	searchButton := widget.NewButton("Search", func() {
+		var targets []SolarSystem
+		for _, name := range stagingChecks.Selected {
+			if solarSystem, found := FindSystemByName(name); found {
+				targets = append(targets, solarSystem)
+			}
+		}
+		filter := ReachFilter{Sov: sovInput.Text}
+		if regionSelect.SelectedIndex() > 0 {
+			filter.Region = regionSelect.Selected
+		}
+		var err error
+		systems, err = GetSystemsInReach(targets, ShipRanges[profile], filter)
+		if err != nil {
+			statusText.SetText(err.Error())
+		} else {
+			statusText.SetText(fmt.Sprintf("%d systems can reach %d stagings with %s range", len(systems), len(targets), profile))
+		}
+		resultsList.Refresh()
+	})
+
+	settings := container.NewVBox(
+		widget.NewLabel("Range options:"),
+		profileSelect,
+		regionSelect,
+		sovInput,
+		searchButton,
+		widget.NewLabel("Stagings:"),
+	)
+	window := app.NewWindow("Eve Sonar who can reach")
+	window.SetContent(container.NewHSplit(
+		container.NewBorder(settings, nil, nil, nil, container.NewVScroll(stagingChecks)),
+		container.NewBorder(statusText, nil, nil, nil, resultsList),
+	))
+	window.Resize(fyne.NewSize(800, 600))
+	window.Show()
+}
+
+// showCoverageWindow what each staging of a list reaches, where they overlap and where another staging would help most.
+func showCoverageWindow(app fyne.App) {
+	window := app.NewWindow("Eve Sonar coverage")
+	resultText := widget.NewLabel("")
+	resultText.Wrapping = fyne.TextWrapWord
+	var suggestion *CoverageSuggestion
+	addSuggestionButton := widget.NewButton("Add suggestion to list", nil)
+	addSuggestionButton.Disable()
+
+	listSelect := widget.NewSelect(GetStagingListNames(), nil)
+	listSelect.SetSelected(DefaultStagingList)
+	profileSelect := widget.NewSelect(ShipRangeNames, nil)
+	profileSelect.SetSelected("Capitals")
+
+	analyze := func() {
+		analysis, err := AnalyzeCoverage(listSelect.Selected, profileSelect.Selected)
+		if err != nil {
+			resultText.SetText(err.Error())
+			return
+		}
+		resultText.SetText(coverageText(analysis))
+		suggestion = analysis.Suggestion
+		if suggestion == nil {
+			addSuggestionButton.Disable()
+		} else {
+			addSuggestionButton.Enable()
+		}
+	}
+	addSuggestionButton.OnTapped = func() {
+		if suggestion == nil {
+			return
+		}
+		if _, err := AddStagingSystem(listSelect.Selected, suggestion.System.Name, ""); err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+		analyze()
+	}
+
+	toolbar := container.NewHBox(listSelect, profileSelect, widget.NewButton("Analyze", analyze), addSuggestionButton)
+	window.SetContent(container.NewBorder(toolbar, nil, nil, nil, container.NewVScroll(resultText)))
+	window.Resize(fyne.NewSize(700, 600))
+	window.Show()
+	analyze()
+}
+
+// showMatrixWindow the distances between every pair of stagings in a list, pairs within the picked range
+// profile are highlighted.
+func showMatrixWindow(app fyne.App) {
+	window := app.NewWindow("Eve Sonar staging distances")
+	var matrix StagingMatrix
+	statusText := widget.NewLabel("")
+	statusText.Wrapping = fyne.TextWrapWord
+	listSelect := widget.NewSelect(GetStagingListNames(), nil)
+	listSelect.SetSelected(DefaultStagingList)
+	profileSelect := widget.NewSelect(ShipRangeNames, nil)
+	profileSelect.SetSelected("Capitals")
+
+	matrixTable := widget.NewTable(
+		func() (int, int) { return len(matrix.Systems) + 1, len(matrix.Systems) + 1 },
+		func() fyne.CanvasObject {
+			return container.NewMax(canvas.NewRectangle(color.Transparent), container.NewPadded(canvas.NewText("", theme.ForegroundColor())))
+		},
+		func(id widget.TableCellID, cell fyne.CanvasObject) {
+			background := cell.(*fyne.Container).Objects[0].(*canvas.Rectangle)
+			text := cell.(*fyne.Container).Objects[1].(*fyne.Container).Objects[0].(*canvas.Text)
+			background.FillColor, text.Text, text.TextStyle = color.Transparent, "", fyne.TextStyle{}
+			switch {
+			case id.Row == 0 && id.Col > 0:
+				text.Text, text.TextStyle.Bold = matrix.Systems[id.Col-1].Name, true
+			case id.Col == 0 && id.Row > 0:
+				text.Text, text.TextStyle.Bold = matrix.Systems[id.Row-1].Name, true
+			case id.Row > 0 && id.Row != id.Col:
+				distance := matrix.Distances[id.Row-1][id.Col-1]
+				text.Text = fmt.Sprintf("%.2f LY", distance.LightYears)
+				if distance.GateJumps != NoGateRoute {
+					text.Text += fmt.Sprintf(" (%d)", distance.GateJumps)
+				}
+				if containsString(distance.Profiles, profileSelect.Selected) {
+					background.FillColor = theme.FocusColor()
+				}
+			}
+			background.Refresh()
+			text.Refresh()
+		},
+	)
+	update := func() {
+		var err error
+		if matrix, err = GetStagingMatrix(listSelect.Selected); err != nil {
+			statusText.SetText(err.Error())
+			return
+		}
+		for i := 0; i <= len(matrix.Systems); i++ {
+			matrixTable.SetColumnWidth(i, 130)
+		}
+		pairs := matrix.PairsWithin(profileSelect.Selected)
+		statusText.SetText(fmt.Sprintf("%d stagings. Pairs within %s range are highlighted, %d of them. Gate jumps are in brackets.",
+			len(matrix.Systems), profileSelect.Selected, len(pairs)))
+		matrixTable.Refresh()
+	}
+	listSelect.OnChanged = func(string) { update() }
+	profileSelect.OnChanged = func(string) { update() }
+
+	exportButton := widget.NewButton("Export CSV", func() {
+		saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil || writer == nil {
+				return
+			}
+			defer writer.Close()
+			if err := WriteStagingMatrixCSV(writer, matrix); err != nil {
+				dialog.ShowError(err, window)
+			}
+		}, window)
+		saveDialog.SetFileName(matrix.List + " distances.csv")
+		saveDialog.Show()
+	})
+
+	toolbar := container.NewHBox(listSelect, widget.NewLabel("Highlight"), profileSelect, exportButton)
+	window.SetContent(container.NewBorder(container.NewVBox(toolbar, statusText), nil, nil, nil, matrixTable))
+	window.Resize(fyne.NewSize(900, 600))
+	window.Show()
+	update()
+}
+
+// coverageText a coverage analysis as lines of text.
+func coverageText(analysis CoverageAnalysis) string {
+	lines := []string{fmt.Sprintf("%s reaches %d systems with %s range, %d of them from more than one staging.",
+		analysis.List, analysis.Covered, analysis.Profile, analysis.Overlapping)}
+	if suggestion := analysis.Suggestion; suggestion != nil {
+		name := suggestion.System.Name
+		if suggestion.Region != "" {
+			name += " (" + suggestion.Region + ")"
+		}
+		lines = append(lines, fmt.Sprintf("Staging in %s would reach %d more systems.", name, suggestion.NewSystems))
+	}
+	lines = append(lines, "", "Stagings:")
+	for _, staging := range analysis.Stagings {
+		lines = append(lines, fmt.Sprintf("%s reaches %d systems, %d no other staging does. %s",
+			staging.System.Name, len(staging.Systems), staging.Unique, strings.Join(staging.Regions, ", ")))
+	}
+	if len(analysis.Overlaps) > 0 {
+		lines = append(lines, "", "Overlaps:")
+	}
+	for _, overlap := range analysis.Overlaps {
+		lines = append(lines, fmt.Sprintf("%s and %s both reach %d systems", overlap.From, overlap.To, overlap.Systems))
+	}
+	if len(analysis.Regions) > 0 {
+		lines = append(lines, "", "Regions:")
+	}
+	for _, region := range analysis.Regions {
+		lines = append(lines, fmt.Sprintf("%s %d of %d systems", region.Region, region.Covered, region.Total))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// showPortalWindow the stagings a Titan or Black Ops bridge from the current system can land a fleet on, with fuel.
+func showPortalWindow(app fyne.App) {
+	window := app.NewWindow("Eve Sonar Titan and Blops bridges")
+	var landings []PortalLanding
+	resultsList := widget.NewList(
+		func() int { return len(landings) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			landing := landings[id]
+			text := fmt.Sprintf("%s %.1f  %.2f LY  %d isotopes", landing.System.Name, DisplaySecurity(landing.System.Sec), landing.LightYears, landing.Isotopes)
+			for _, detail := range []string{landing.Region, landing.Owner, landing.Sov} {
+				if detail != "" {
+					text += "  " + detail
+				}
+			}
+			item.(*widget.Label).SetText(text)
+		},
+	)
+	statusText := widget.NewLabel("")
+	statusText.Wrapping = fyne.TextWrapWord
+	massInput := widget.NewEntry()
+	massInput.SetPlaceHolder("Fleet mass, million kg")
+	rateInput := widget.NewEntry()
+	portalType := PortalTypes[0]
+
+	update := func() {
+		mass, err := strconv.ParseFloat(strings.TrimSpace(massInput.Text), 64)
+		if err != nil {
+			mass = 0
+		}
+		from, found := FindSystemByID(getCurrentSolarSystemID())
+		if !found {
+			landings = nil
+			statusText.SetText("Pick a system or track a character to see where a bridge from it can land.")
+			resultsList.Refresh()
+			return
+		}
+		landings = GetPortalLandings(from, portalType, mass*1e6)
+		statusText.SetText(fmt.Sprintf("A %s bridge from %s reaches %.1f LY and moves %s. %d stagings can be bridged to.",
+			portalType.Name, from.Name, ToLightYears(ShipRanges[portalType.Profile]), strings.Join(portalType.Hulls, ", "), len(landings)))
+		resultsList.Refresh()
+	}
+
+	typeNames := make([]string, len(PortalTypes))
+	for i, portal := range PortalTypes {
+		typeNames[i] = portal.Name
+	}
+	typeSelect := widget.NewSelect(typeNames, func(name string) {
+		portalType, _ = FindPortalType(name)
+		rateInput.SetText(strconv.FormatFloat(GetPortalFuelRate(portalType), 'f', -1, 64))
+		update()
+	})
+	rateInput.OnSubmitted = func(text string) {
+		rate, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+		if err == nil {
+			err = SavePortalFuelRate(portalType, rate)
+		}
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("fuel rate %q: %w", text, err), window)
+			return
+		}
+		update()
+	}
+	massInput.OnSubmitted = func(string) { update() }
+
+	form := widget.NewForm(
+		widget.NewFormItem("Bridge", typeSelect),
+		widget.NewFormItem("Fleet mass", massInput),
+		widget.NewFormItem("Isotopes per LY per million kg", rateInput),
+	)
+	window.SetContent(container.NewBorder(
+		container.NewVBox(form, widget.NewButton("Check from current system", update), statusText),
+		nil, nil, nil, resultsList,
+	))
+	window.Resize(fyne.NewSize(700, 600))
+	window.Show()
+	typeSelect.SetSelected(portalType.Name)
+}
+
+// showFuelWindow the race, skills and hulls the fuel shown for each staging is worked out with.
+func showFuelWindow(app fyne.App) {
+	window := app.NewWindow("Eve Sonar jump fuel")
+	settings := GetFuelSettings()
+	levels := []string{"0", "1", "2", "3", "4", "5"}
+
+	raceSelect := widget.NewSelect(FuelRaces, nil)
+	raceSelect.SetSelected(settings.Race)
+	conservationSelect := widget.NewSelect(levels, nil)
+	conservationSelect.SetSelected(strconv.Itoa(settings.JumpFuelConservation))
+	jumpFreightersSelect := widget.NewSelect(levels, nil)
+	jumpFreightersSelect.SetSelected(strconv.Itoa(settings.JumpFreighters))
+	form := widget.NewForm(
+		widget.NewFormItem("Race", raceSelect),
+		widget.NewFormItem("Jump Fuel Conservation", conservationSelect),
+		widget.NewFormItem("Jump Freighters", jumpFreightersSelect),
+	)
+	hullSelects := make(map[string]*widget.Select)
+	for _, profile := range ShipRangeNames {
+		var hullNames []string
+		for _, hull := range ProfileHulls(profile) {
+			hullNames = append(hullNames, hull.Name)
+		}
+		hullSelects[profile] = widget.NewSelect(hullNames, nil)
+		hullSelects[profile].SetSelected(settings.Hull(profile).Name)
+		form.Append(profile+" hull", hullSelects[profile])
+	}
+
+	statusText := widget.NewLabel("")
+	statusText.Wrapping = fyne.TextWrapWord
+	saveButton := widget.NewButton("Save", func() {
+		settings.Race = raceSelect.Selected
+		settings.JumpFuelConservation, _ = strconv.Atoi(conservationSelect.Selected)
+		settings.JumpFreighters, _ = strconv.Atoi(jumpFreightersSelect.Selected)
+		for profile, hullSelect := range hullSelects {
+			settings.Hulls[profile] = hullSelect.Selected
+		}
+		if err := SaveFuelSettings(settings); err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+		statusText.SetText(fmt.Sprintf("Saved, jumps burn %s.", RaceIsotopes[settings.Race]))
+		updateStagingTable(rangeSettings, getCurrentSolarSystemID())
+	})
+
+	window.SetContent(container.NewVBox(
+		widget.NewLabel("Fuel is worked out from each hull's rough fuel need per light year."),
+		form,
+		saveButton,
+		statusText,
+	))
+	window.Resize(fyne.NewSize(420, 400))
+	window.Show()
+}
+
+// showTimelineWindow the recorded moves of one or every character, newest first.
+func showTimelineWindow(app fyne.App) {
+	window := app.NewWindow("Eve Sonar movement timeline")
+	var moves []LocationMove
+	movesList := widget.NewList(
+		func() int { return len(moves) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			move := moves[len(moves)-1-id]
+			text := fmt.Sprintf("%s  %s  %s %.1f", move.Time.UTC().Format("2006-01-02 15:04"), move.Character, move.System.Name, DisplaySecurity(move.System.Sec))
+			if move.Region != "" {
+				text += "  " + move.Region
+			}
+			switch {
+			case move.From == "":
+			case move.Gated:
+				text += "  gate from " + move.From
+			case move.Bridged:
+				text += "  bridge from " + move.From
+			case move.Jumped:
+				text += fmt.Sprintf("  jumped %.2f LY from %s, cooldown %s", move.LightYears, move.From, FormatMinutes(move.CooldownMinutes))
+			default:
+				text += fmt.Sprintf("  %.2f LY from %s", move.LightYears, move.From)
+			}
+			if move.FatigueMinutes > 0 {
+				text += "  fatigue " + FormatMinutes(move.FatigueMinutes)
+			}
+			item.(*widget.Label).SetText(text + "  (" + move.Source + ")")
+		},
+	)
+	statusText := widget.NewLabel("")
+	statusText.Wrapping = fyne.TextWrapWord
+	const allCharacters = "All characters"
+	characterFilter := widget.NewSelect(append([]string{allCharacters}, GetLocationHistoryCharacters()...), nil)
+
+	update := func() {
+		character := characterFilter.Selected
+		if character == allCharacters {
+			character = ""
+		}
+		moves = GetLocationHistory(character, time.Time{})
+		var jumps int
+		var lightYears float64
+		var lastJump LocationMove
+		for _, move := range moves {
+			if move.Jumped {
+				jumps++
+				lightYears += move.LightYears
+				lastJump = move
+			}
+		}
+		status := fmt.Sprintf("%d moves kept, %d of them jumps for %.2f LY in total. Moves are kept for %d days, times are EVE time.",
+			len(moves), jumps, lightYears, GetLocationHistoryDays())
+		if character != "" {
+			fatigue, cooldown := JumpFatigueAt(lastJump, time.Now())
+			if fatigue > 0 {
+				status += " Estimated fatigue left " + FormatMinutes(fatigue) + "."
+			}
+			if cooldown > 0 {
+				status += " Jump cooldown left " + FormatMinutes(cooldown) + "."
+			}
+		}
+		if !HasStargates() {
+			status += " Without stargate data moves can't be told apart so fatigue isn't estimated."
+		}
+		if !IsLocationHistoryEnabled() {
+			status += " Location history is off, tick Keep location history to record moves."
+		}
+		statusText.SetText(status)
+		movesList.Refresh()
+	}
+	characterFilter.OnChanged = func(string) { update() }
+
+	exportButton := widget.NewButton("Export CSV", func() {
+		saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil || writer == nil {
+				return
+			}
+			defer writer.Close()
+			if err := WriteLocationHistoryCSV(writer, moves); err != nil {
+				dialog.ShowError(err, window)
+			}
+		}, window)
+		saveDialog.SetFileName("location history.csv")
+		saveDialog.Show()
+	})
+	clearButton := widget.NewButton("Clear", func() {
+		dialog.ShowConfirm("Clear location history", "Delete every recorded move?", func(clear bool) {
+			if !clear {
+				return
+			}
+			if err := ClearLocationHistory(); err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+			characterFilter.Options = []string{allCharacters}
+			characterFilter.SetSelected(allCharacters)
+			update()
+		}, window)
+	})
+
+	toolbar := container.NewHBox(characterFilter, widget.NewButton("Refresh", update), exportButton, clearButton)
+	window.SetContent(container.NewBorder(container.NewVBox(toolbar, statusText), nil, nil, nil, movesList))
+	window.Resize(fyne.NewSize(800, 600))
+	window.Show()
+	characterFilter.SetSelected(allCharacters)
+}
+
+// updateStarMap shows the current system, ticked ranges and stagings on the star map when it is open.
+func updateStarMap() {
+	starMap := starMap
+	if starMap == nil {
+		return
+	}
+	var ranges []string
+	for _, rangeName := range ShipRangeNames {
+		if isRangeSelected(rangeSettings, rangeName) {
+			ranges = append(ranges, rangeName)
+		}
+	}
+	starMap.SetRanges(ranges)
+	starMap.SetStagings(GetAllStagings())
+	starMap.SetJumpBridges(GetJumpBridges())
+	starMap.SetCurrentSystem(getCurrentSolarSystemID())
+}
+
+// showJumpBridgesDialog edits the saved jump bridges as "From » To" lines, saving replaces them all.
+func showJumpBridgesDialog(window fyne.Window) {
+	bridgesInput := widget.NewMultiLineEntry()
+	bridgesInput.SetPlaceHolder("1DQ1-A » 8QT-H4 - structure name\nOne jump bridge per line")
+	bridgesInput.SetText(FormatJumpBridges(GetJumpBridges()))
+	ownerInput := widget.NewEntry()
+	ownerInput.SetPlaceHolder("Owner of new bridges")
+	content := container.NewBorder(nil, ownerInput, nil, nil, container.NewScroll(bridgesInput))
+	bridgesDialog := dialog.NewCustomConfirm("Jump bridges", "Save", "Cancel", content, func(save bool) {
+		if !save {
+			return
+		}
+		// Saved bridges keep their owner, only new ones get the owner typed in
+		saved := make(map[string]struct{})
+		for _, bridge := range GetJumpBridges() {
+			saved[jumpBridgeKey(bridge)] = struct{}{}
+		}
+		bridges, rejected := ParseJumpBridges(bridgesInput.Text, "")
+		for i := range bridges {
+			if _, found := saved[jumpBridgeKey(bridges[i])]; !found {
+				bridges[i].Owner = strings.TrimSpace(ownerInput.Text)
+			}
+		}
+		if err := ImportJumpBridges(bridges, true); err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+		updateStarMap()
+		showRejectedLines(window, "", rejected)
+	}, window)
+	bridgesDialog.Resize(fyne.NewSize(500, 400))
+	bridgesDialog.Show()
+}
+
+// buildThresholdEntry number entry for an activity threshold, blank or invalid input means no threshold.
+func buildThresholdEntry(placeHolder string, setThreshold func(int)) *widget.Entry {
+	thresholdInput := widget.NewEntry()
+	thresholdInput.SetPlaceHolder(placeHolder)
+	thresholdInput.OnChanged = func(text string) {
+		threshold, err := strconv.Atoi(strings.TrimSpace(text))
+		if err != nil {
+			threshold = 0
+		}
+		setThreshold(threshold)
+		updateStagingTable(rangeSettings, getCurrentSolarSystemID())
+	}
+	return thresholdInput
+}
+
+// refreshActivity keeps one of the activity caches fresh, refreshing as soon as ESI has new data.
+func refreshActivity(refresh func() (time.Time, error)) {
+	for {
+		expires, err := refresh()
+		if err != nil {
+			log.Println("Error refreshing system activity:", err)
+			expires = time.Now().Add(time.Minute * 5)
+		} else {
+			updateStagingTable(rangeSettings, getCurrentSolarSystemID())
+		}
+		time.Sleep(time.Until(expires))
+	}
+}
+
+// updateSovereigntyChangesText highlights tracked stagings whose sovereignty changed, newest first.
+func updateSovereigntyChangesText(changesText *widget.Label, changes []SovereigntyChange) {
+	if len(changes) == 0 {
+		return
+	}
+	sovereigntyChanges = append(changes, sovereigntyChanges...)
+	if len(sovereigntyChanges) > maxSovereigntyChanges {
+		sovereigntyChanges = sovereigntyChanges[:maxSovereigntyChanges]
+	}
+
+	text := "Sovereignty changes:\n"
+	for _, change := range sovereigntyChanges {
+		oldHolder, newHolder := change.OldHolder, change.NewHolder
+		if oldHolder == "" {
+			oldHolder = "Unclaimed"
+		}
+		if newHolder == "" {
+			newHolder = "Unclaimed"
+		}
+		text += fmt.Sprintf("%s %s: %s -> %s\n", change.Time.Format("15:04"), change.System, oldHolder, newHolder)
+	}
+	changesText.SetText(text)
 }
 
 func openWebpage(urlStr string, app fyne.App) error {
@@ -0,0 +1,211 @@
+package eveSolarSystems
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// IntelReport a message from an intel channel and the systems named in it.
+type IntelReport struct {
+	Time     time.Time
+	Channel  string
+	Reporter string
+	Message  string
+	Systems  []SolarSystem
+}
+
+// IntelAlert a reported system that is within range or the gate jump threshold of the current system or a staging.
+type IntelAlert struct {
+	System     SolarSystem `json:"system"`
+	Near       string      `json:"near"`
+	LightYears float64     `json:"light_years"`
+	// GateJumps NoGateRoute when there is no gate route or no stargate data
+	GateJumps int `json:"gate_jumps"`
+}
+
+const (
+	intelChannelsSetting  string = "intelChannels"
+	intelGateJumpsSetting string = "intelGateJumps"
+	// IntelGateJumpsOff alerts only go by jump range
+	IntelGateJumpsOff = -1
+	// intelDuplicateWindow the same report seen by several logged in characters is only shown once
+	intelDuplicateWindow = time.Minute
+)
+
+// GetIntelChannels returns the saved intel channel names.
+func GetIntelChannels() []string {
+	var channels []string
+	for _, channel := range strings.Split(GetSetting(intelChannelsSetting), ",") {
+		if channel = strings.TrimSpace(channel); channel != "" {
+			channels = append(channels, channel)
+		}
+	}
+	return channels
+}
+
+// SaveIntelChannels saves the intel channel names to monitor.
+func SaveIntelChannels(channels []string) error {
+	return SaveSetting(intelChannelsSetting, strings.Join(channels, ","))
+}
+
+// GetIntelGateJumps the gate jumps reported systems alert within, IntelGateJumpsOff until one is saved.
+func GetIntelGateJumps() int {
+	if jumps, err := strconv.Atoi(GetSetting(intelGateJumpsSetting)); err == nil && jumps >= 0 {
+		return jumps
+	}
+	return IntelGateJumpsOff
+}
+
+// SaveIntelGateJumps saves the gate jumps reported systems alert within, IntelGateJumpsOff turns it off.
+func SaveIntelGateJumps(jumps int) error {
+	if jumps < IntelGateJumpsOff {
+		return fmt.Errorf("gate jumps can't be negative")
+	}
+	if jumps == IntelGateJumpsOff {
+		return SaveSetting(intelGateJumpsSetting, "")
+	}
+	return SaveSetting(intelGateJumpsSetting, strconv.Itoa(jumps))
+}
+
+// CheckIntelReport returns the reported systems within jumpRange, or within gateJumps gate jumps unless it is
+// IntelGateJumpsOff, of the current system or any staging.
+func CheckIntelReport(report IntelReport, jumpRange float64, gateJumps int, currentSolarSystemID string) []IntelAlert {
+	targets := make(map[string]SolarSystem)
+	if currentSystem, found := FindSystemByID(currentSolarSystemID); found {
+		targets["Current system "+currentSystem.Name] = currentSystem
+	}
+	for _, staging := range GetStagingSystemNames() {
+		if stagingSystem, found := FindSystemByName(staging); found {
+			targets[stagingSystem.Name] = stagingSystem
+		}
+	}
+
+	// The systems within the gate jump threshold of each target, by target then system ID
+	withinGates := make(map[string]map[string]int)
+	if gateJumps != IntelGateJumpsOff && HasStargates() {
+		for near, target := range targets {
+			withinGates[near] = map[string]int{target.ID: 0}
+			systems, _ := SystemsWithinGates(target, gateJumps)
+			for _, system := range systems {
+				withinGates[near][system.System.ID] = system.Jumps
+			}
+		}
+	}
+
+	var alerts []IntelAlert
+	for _, reported := range report.Systems {
+		for near, target := range targets {
+			distance := Distance3D(reported.Coordinates, target.Coordinates)
+			jumps, withinGateJumps := withinGates[near][reported.ID]
+			if distance > jumpRange && !withinGateJumps {
+				continue
+			}
+			alert := IntelAlert{
+				System:     reported,
+				Near:       near,
+				LightYears: ToLightYears(distance),
+				GateJumps:  jumps,
+			}
+			if !withinGateJumps {
+				alert.GateJumps, _ = GateJumps(reported, target)
+			}
+			alerts = append(alerts, alert)
+		}
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].LightYears < alerts[j].LightYears
+	})
+
+	return alerts
+}
+
+// IntelMonitor tails intel channel logs and reports every message that names a system.
+type IntelMonitor struct {
+	dir      string
+	channels []string
+	onReport func(report IntelReport)
+	stop     chan struct{}
+	lock     sync.Mutex
+}
+
+// NewIntelMonitor creates a monitor for the given channels in the Chatlogs directory dir, call Start to begin tailing.
+func NewIntelMonitor(dir string, channels []string, onReport func(report IntelReport)) *IntelMonitor {
+	return &IntelMonitor{
+		dir:      dir,
+		channels: channels,
+		onReport: onReport,
+	}
+}
+
+// Start tails the intel logs in the background until Stop is called, only new messages are reported.
+func (m *IntelMonitor) Start() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.stop != nil {
+		return
+	}
+	m.stop = make(chan struct{})
+
+	go func(stop chan struct{}) {
+		tailer := newChatLogTailer(m.dir, m.isIntelChannel, false)
+		recent := make(map[string]time.Time)
+		ticker := time.NewTicker(chatLogPollInterval)
+		defer ticker.Stop()
+		for {
+			lines, _ := tailer.poll()
+			for _, line := range lines {
+				key := line.Channel + line.Time.String() + line.Sender + line.Message
+				if _, duplicate := recent[key]; duplicate {
+					continue
+				}
+				recent[key] = time.Now()
+
+				systems := MatchSystemsInText(line.Message)
+				if len(systems) == 0 || m.onReport == nil {
+					continue
+				}
+				m.onReport(IntelReport{
+					Time:     line.Time,
+					Channel:  line.Channel,
+					Reporter: line.Sender,
+					Message:  line.Message,
+					Systems:  systems,
+				})
+			}
+			for key, seen := range recent {
+				if time.Since(seen) > intelDuplicateWindow {
+					delete(recent, key)
+				}
+			}
+
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}(m.stop)
+}
+
+// Stop stops tailing the intel logs.
+func (m *IntelMonitor) Stop() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.stop != nil {
+		close(m.stop)
+		m.stop = nil
+	}
+}
+
+func (m *IntelMonitor) isIntelChannel(name string) bool {
+	for _, channel := range m.channels {
+		if strings.EqualFold(channel, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,299 @@
+package eveSolarSystems
+
+import (
+	"encoding/json"
+	"fmt"
+	bolt "go.etcd.io/bbolt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// JumpBridge an Ansiblex jump bridge between two systems, usable both ways.
+type JumpBridge struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Owner string `json:"owner,omitempty"`
+	Note  string `json:"note,omitempty"`
+}
+
+const (
+	// jumpBridgesBucket saved jump bridges as JSON, keyed by the lower system ID then the higher one
+	jumpBridgesBucket string = "jumpBridges"
+	// JumpBridgeLightYears the furthest apart the two ends of an Ansiblex can be
+	JumpBridgeLightYears float64 = 5
+)
+
+// jumpBridgeSeparators what goes between the two systems of a bridge line, longest first so "<->" isn't read as ">"
+var jumpBridgeSeparators = []string{"»", "<->", "<>", "-->", "->", ">"}
+
+// String the bridge as a line in the "From » To - note" format ParseJumpBridges reads.
+func (b JumpBridge) String() string {
+	text := b.From + " » " + b.To
+	if b.Note != "" {
+		text += " - " + b.Note
+	}
+	return text
+}
+
+// GetJumpBridges returns every saved jump bridge sorted by the system they start from.
+func GetJumpBridges() []JumpBridge {
+	db, err := openDB()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	var bridges []JumpBridge
+	err = db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(jumpBridgesBucket))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(key, value []byte) error {
+			var bridge JumpBridge
+			if err := json.Unmarshal(value, &bridge); err != nil {
+				return err
+			}
+			bridges = append(bridges, bridge)
+			return nil
+		})
+	})
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	sort.Slice(bridges, func(i, j int) bool {
+		return bridges[i].From < bridges[j].From
+	})
+	return bridges
+}
+
+// ValidateJumpBridge checks both systems exist and a bridge could be anchored between them, returning it with the
+// system names as the game spells them.
+func ValidateJumpBridge(bridge JumpBridge) (JumpBridge, error) {
+	from, err := ValidateStagingSystem(bridge.From)
+	if err != nil {
+		return JumpBridge{}, err
+	}
+	to, err := ValidateStagingSystem(bridge.To)
+	if err != nil {
+		return JumpBridge{}, err
+	}
+	if from.ID == to.ID {
+		return JumpBridge{}, fmt.Errorf("a jump bridge can't go from %s to itself", from.Name)
+	}
+	for _, solarSystem := range []SolarSystem{from, to} {
+		if !strings.HasPrefix(solarSystem.ID, newEdenIDPrefix) || DisplaySecurity(solarSystem.Sec) > 0 {
+			return JumpBridge{}, fmt.Errorf("%s isn't nullsec, jump bridges need sovereignty", solarSystem.Name)
+		}
+	}
+	if lightYears := ToLightYears(Distance3D(from.Coordinates, to.Coordinates)); lightYears > JumpBridgeLightYears {
+		return JumpBridge{}, fmt.Errorf("%s and %s are %.2f LY apart, jump bridges reach %.0f LY", from.Name, to.Name, lightYears, JumpBridgeLightYears)
+	}
+
+	bridge.From, bridge.To = from.Name, to.Name
+	bridge.Owner, bridge.Note = strings.TrimSpace(bridge.Owner), strings.TrimSpace(bridge.Note)
+	return bridge, nil
+}
+
+// AddJumpBridge validates and saves a jump bridge, replacing the owner and note when the bridge is already saved.
+// Each system only has room for one bridge.
+func AddJumpBridge(bridge JumpBridge) (JumpBridge, error) {
+	bridge, err := ValidateJumpBridge(bridge)
+	if err != nil {
+		return JumpBridge{}, err
+	}
+	existing := GetJumpBridges()
+	if err := checkJumpBridgeSystems(bridge, existing); err != nil {
+		return JumpBridge{}, err
+	}
+	if err := saveJumpBridges(append(existing, bridge)); err != nil {
+		return JumpBridge{}, err
+	}
+	return bridge, nil
+}
+
+// RemoveJumpBridge removes the bridge starting or ending in a system, false when the system has no bridge.
+func RemoveJumpBridge(systemName string) (JumpBridge, bool, error) {
+	bridges := GetJumpBridges()
+	for i, bridge := range bridges {
+		if strings.EqualFold(bridge.From, strings.TrimSpace(systemName)) || strings.EqualFold(bridge.To, strings.TrimSpace(systemName)) {
+			err := saveJumpBridges(append(bridges[:i:i], bridges[i+1:]...))
+			return bridge, true, err
+		}
+	}
+	return JumpBridge{}, false, nil
+}
+
+// ParseJumpBridges reads "From » To" lines, anything after the destination is the bridge's note, e.g. the
+// structure name. "->", "-->", "<->" and ">" work instead of "»". Every bridge gets the owner given.
+func ParseJumpBridges(text string, owner string) ([]JumpBridge, []RejectedLine) {
+	var bridges []JumpBridge
+	var rejected []RejectedLine
+	seen := make(map[string]int)
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		bridge, err := parseJumpBridgeLine(line)
+		if err == nil {
+			bridge.Owner = owner
+			bridge, err = ValidateJumpBridge(bridge)
+		}
+		if err != nil {
+			rejected = append(rejected, RejectedLine{Line: i + 1, Text: line, Reason: err.Error()})
+			continue
+		}
+		if firstLine, duplicate := jumpBridgeConflict(bridge, seen); duplicate {
+			rejected = append(rejected, RejectedLine{Line: i + 1, Text: line, Reason: fmt.Sprintf("line %d already has a bridge in %s or %s", firstLine, bridge.From, bridge.To)})
+			continue
+		}
+		seen[bridge.From], seen[bridge.To] = i+1, i+1
+		bridges = append(bridges, bridge)
+	}
+	return bridges, rejected
+}
+
+// ImportJumpBridges saves parsed bridges, replacing every saved bridge when replace is true. Otherwise saved bridges
+// sharing a system with an imported one are replaced by it. Imported bridges without an owner keep the saved owner.
+func ImportJumpBridges(bridges []JumpBridge, replace bool) error {
+	existing := GetJumpBridges()
+	owners := make(map[string]string)
+	for _, bridge := range existing {
+		owners[jumpBridgeKey(bridge)] = bridge.Owner
+	}
+	seen := make(map[string]int)
+	for i, bridge := range bridges {
+		if bridge.Owner == "" {
+			bridges[i].Owner = owners[jumpBridgeKey(bridge)]
+		}
+		seen[bridge.From], seen[bridge.To] = i+1, i+1
+	}
+
+	var saved []JumpBridge
+	if !replace {
+		for _, bridge := range existing {
+			if _, conflict := jumpBridgeConflict(bridge, seen); !conflict {
+				saved = append(saved, bridge)
+			}
+		}
+	}
+	return saveJumpBridges(append(saved, bridges...))
+}
+
+// FormatJumpBridges one bridge per line in the format ParseJumpBridges reads.
+func FormatJumpBridges(bridges []JumpBridge) string {
+	var text strings.Builder
+	for _, bridge := range bridges {
+		text.WriteString(bridge.String() + "\n")
+	}
+	return text.String()
+}
+
+// parseJumpBridgeLine splits a line into the two systems and a note, system names can contain spaces so the longest
+// name that matches on each side of the separator is used.
+func parseJumpBridgeLine(line string) (JumpBridge, error) {
+	for _, separator := range jumpBridgeSeparators {
+		before, after, found := strings.Cut(line, separator)
+		if !found {
+			continue
+		}
+		fromWords, toWords := strings.Fields(before), strings.Fields(after)
+		if len(fromWords) == 0 || len(toWords) == 0 {
+			break
+		}
+
+		bridge := JumpBridge{From: strings.Join(fromWords, " ")}
+		for i := 0; i < len(fromWords); i++ {
+			if solarSystem, found := FindSystemByName(strings.Join(fromWords[i:], " ")); found {
+				bridge.From = solarSystem.Name
+				break
+			}
+		}
+		bridge.To = toWords[0]
+		for i := len(toWords); i > 0; i-- {
+			if solarSystem, found := FindSystemByName(strings.Join(toWords[:i], " ")); found {
+				bridge.To = solarSystem.Name
+				bridge.Note = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(strings.Join(toWords[i:], " ")), "-"))
+				break
+			}
+		}
+		return bridge, nil
+	}
+	return JumpBridge{}, fmt.Errorf("no \"»\" between two systems")
+}
+
+// checkJumpBridgeSystems errors when either end of a bridge already has a different bridge.
+func checkJumpBridgeSystems(bridge JumpBridge, existing []JumpBridge) error {
+	for _, other := range existing {
+		if jumpBridgeKey(other) == jumpBridgeKey(bridge) {
+			continue
+		}
+		for _, system := range []string{bridge.From, bridge.To} {
+			if system == other.From || system == other.To {
+				return fmt.Errorf("%s already has a jump bridge, %s", system, other)
+			}
+		}
+	}
+	return nil
+}
+
+// jumpBridgeConflict the line of an earlier bridge in either system of a bridge.
+func jumpBridgeConflict(bridge JumpBridge, seen map[string]int) (int, bool) {
+	for _, system := range []string{bridge.From, bridge.To} {
+		if line, found := seen[system]; found {
+			return line, true
+		}
+	}
+	return 0, false
+}
+
+// jumpBridgeKey the same for both directions of a bridge.
+func jumpBridgeKey(bridge JumpBridge) string {
+	from, _ := FindSystemByName(bridge.From)
+	to, _ := FindSystemByName(bridge.To)
+	if from.ID > to.ID {
+		from, to = to, from
+	}
+	return from.ID + ">" + to.ID
+}
+
+// saveJumpBridges replaces every saved bridge, later bridges replace earlier ones between the same systems.
+func saveJumpBridges(bridges []JumpBridge) error {
+	// Keyed before opening the DB as looking up systems the first time opens it too
+	keys := make([]string, len(bridges))
+	for i, bridge := range bridges {
+		keys[i] = jumpBridgeKey(bridge)
+	}
+
+	db, err := openDB()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	return db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket([]byte(jumpBridgesBucket)) != nil {
+			if err := tx.DeleteBucket([]byte(jumpBridgesBucket)); err != nil {
+				return err
+			}
+		}
+		bucket, err := tx.CreateBucket([]byte(jumpBridgesBucket))
+		if err != nil {
+			return err
+		}
+		for i, bridge := range bridges {
+			value, err := json.Marshal(bridge)
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put([]byte(keys[i]), value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
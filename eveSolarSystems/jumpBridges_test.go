@@ -0,0 +1,92 @@
+package eveSolarSystems
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseJumpBridgeLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want JumpBridge
+		err  bool
+	}{
+		{"guillemet", "Alpha » Nova", JumpBridge{From: "Alpha", To: "Nova"}, false},
+		{"two way arrow is not read as >", "Alpha <-> Nova", JumpBridge{From: "Alpha", To: "Nova"}, false},
+		{"diamond", "Alpha <> Nova", JumpBridge{From: "Alpha", To: "Nova"}, false},
+		{"long arrow", "Alpha --> Nova", JumpBridge{From: "Alpha", To: "Nova"}, false},
+		{"arrow", "Alpha -> Nova", JumpBridge{From: "Alpha", To: "Nova"}, false},
+		{"greater than", "Alpha > Nova", JumpBridge{From: "Alpha", To: "Nova"}, false},
+		{"guillemet before a > in the note", "Alpha » Nova > staging", JumpBridge{From: "Alpha", To: "Nova", Note: "> staging"}, false},
+		{"case and spacing", "  alpha   »   nova ", JumpBridge{From: "Alpha", To: "Nova"}, false},
+		{"multi word origin", "Nova Prime » Sierra", JumpBridge{From: "Nova Prime", To: "Sierra"}, false},
+		{"multi word destination", "Sierra » Nova Prime", JumpBridge{From: "Sierra", To: "Nova Prime"}, false},
+		{"longest destination wins", "Sierra » Nova Prime Ansiblex", JumpBridge{From: "Sierra", To: "Nova Prime", Note: "Ansiblex"}, false},
+		{"note after a dash", "Alpha » Nova - Ansiblex by the sun", JumpBridge{From: "Alpha", To: "Nova", Note: "Ansiblex by the sun"}, false},
+		{"note without a dash", "Alpha » Nova 1DQ gate", JumpBridge{From: "Alpha", To: "Nova", Note: "1DQ gate"}, false},
+		{"unknown destination kept for validation", "Alpha » Nowhere", JumpBridge{From: "Alpha", To: "Nowhere"}, false},
+		{"no separator", "Alpha Nova", JumpBridge{}, true},
+		{"no origin", "» Nova", JumpBridge{}, true},
+		{"no destination", "Alpha »", JumpBridge{}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseJumpBridgeLine(test.line)
+			if (err != nil) != test.err {
+				t.Fatalf("error %v, want error %v", err, test.err)
+			}
+			if got != test.want {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseJumpBridges(t *testing.T) {
+	text := strings.Join([]string{
+		"Alpha » Nova - main",
+		"",
+		"Nowhere » Nova",
+		"Alpha » Foxtrot",
+		"Jita » Old Man Star",
+		"Nova Prime <-> Sierra",
+		"November » Sierra",
+		"Niner Niner",
+		"Foxtrot » Foxtrot",
+	}, "\n")
+	bridges, rejected := ParseJumpBridges(text, "Test Alliance")
+
+	wantBridges := []JumpBridge{
+		{From: "Alpha", To: "Nova", Owner: "Test Alliance", Note: "main"},
+		{From: "Nova Prime", To: "Sierra", Owner: "Test Alliance"},
+	}
+	if len(bridges) != len(wantBridges) {
+		t.Fatalf("got bridges %+v, want %+v", bridges, wantBridges)
+	}
+	for i := range wantBridges {
+		if bridges[i] != wantBridges[i] {
+			t.Errorf("bridge %d is %+v, want %+v", i, bridges[i], wantBridges[i])
+		}
+	}
+
+	wantRejected := []struct {
+		line   int
+		reason string
+	}{
+		{3, `unknown system "Nowhere"`},
+		{4, "line 1 already has a bridge"},
+		{5, "isn't nullsec"},
+		{7, "LY apart"},
+		{8, `no "»"`},
+		{9, "to itself"},
+	}
+	if len(rejected) != len(wantRejected) {
+		t.Fatalf("got rejected %+v, want %d lines", rejected, len(wantRejected))
+	}
+	for i, want := range wantRejected {
+		if rejected[i].Line != want.line || !strings.Contains(rejected[i].Reason, want.reason) {
+			t.Errorf("rejected %+v, want line %d with %q", rejected[i], want.line, want.reason)
+		}
+	}
+}
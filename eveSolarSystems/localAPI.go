@@ -0,0 +1,111 @@
+package eveSolarSystems
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	localAPIAddressSetting string = "localAPIAddress"
+	localAPIEnabledSetting string = "localAPIEnabled"
+	localAPITokenSetting   string = "localAPIToken"
+	// DefaultLocalAPIAddress 8080 is taken by the ESI login callback
+	DefaultLocalAPIAddress = "localhost:8081"
+)
+
+// LocalAPIHandler serves the local HTTP API. Set by main so the app can turn the API on without importing it.
+var LocalAPIHandler http.Handler
+
+var localAPIServer *http.Server
+var localAPILock sync.Mutex
+
+// GetLocalAPIAddress returns the saved address for the local API or the default.
+func GetLocalAPIAddress() string {
+	if address := GetSetting(localAPIAddressSetting); address != "" {
+		return address
+	}
+	return DefaultLocalAPIAddress
+}
+
+// IsLocalAPIEnabled true when the local API was left on last time the app was open.
+func IsLocalAPIEnabled() bool {
+	return GetSetting(localAPIEnabledSetting) == "true"
+}
+
+// GetLocalAPIToken the token changes over the local API have to send, empty when none is needed.
+func GetLocalAPIToken() string {
+	return GetSetting(localAPITokenSetting)
+}
+
+// NewLocalAPIToken saves a new random token for changes over the local API, replacing the old one.
+func NewLocalAPIToken() (string, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	encoded := hex.EncodeToString(token)
+	return encoded, SaveSetting(localAPITokenSetting, encoded)
+}
+
+// ClearLocalAPIToken lets changes over the local API through without a token again.
+func ClearLocalAPIToken() error {
+	return SaveSetting(localAPITokenSetting, "")
+}
+
+// StartLocalAPI listens on address and serves LocalAPIHandler until StopLocalAPI is called.
+func StartLocalAPI(address string) error {
+	localAPILock.Lock()
+	defer localAPILock.Unlock()
+	if localAPIServer != nil {
+		return errors.New("local API is already running")
+	}
+	if LocalAPIHandler == nil {
+		return errors.New("local API is not available")
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	localAPIServer = &http.Server{Handler: LocalAPIHandler}
+	go func(server *http.Server) {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Println("Error serving local API:", err)
+		}
+	}(localAPIServer)
+
+	if err := SaveSetting(localAPIAddressSetting, address); err != nil {
+		return err
+	}
+	return SaveSetting(localAPIEnabledSetting, "true")
+}
+
+// StopLocalAPI shuts the local API down if it is running.
+func StopLocalAPI() error {
+	localAPILock.Lock()
+	defer localAPILock.Unlock()
+	if localAPIServer == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := localAPIServer.Shutdown(ctx)
+	if err != nil {
+		// Event streams stay open until the client leaves so cut them off
+		err = localAPIServer.Close()
+	}
+	localAPIServer = nil
+	if saveErr := SaveSetting(localAPIEnabledSetting, "false"); saveErr != nil {
+		return saveErr
+	}
+
+	return err
+}
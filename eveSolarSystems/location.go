@@ -0,0 +1,260 @@
+package eveSolarSystems
+
+import (
+	"github.com/sythe7448/Eve-Sonar/api"
+	"sync"
+	"time"
+)
+
+// LocationEvent a character seen in a solar system by one of the location sources.
+type LocationEvent struct {
+	Character     string    `json:"character"`
+	SolarSystemID string    `json:"solar_system_id"`
+	Time          time.Time `json:"time"`
+	Source        string    `json:"source"`
+}
+
+// LocationSource anything that can report where characters are, e.g. ESI, manual input or chat logs.
+// Start sends events until Stop is called.
+type LocationSource interface {
+	Name() string
+	Start(events chan<- LocationEvent)
+	Stop()
+}
+
+// Location source names
+const (
+	SourceESI     = "ESI"
+	SourceManual  = "Manual"
+	SourceChatLog = "Chat log"
+)
+
+// esiPollInterval how often the ESI location endpoint is polled, it is cached for 5 seconds
+const esiPollInterval = time.Second * 10
+
+// subscriberBuffer events a slow subscriber can fall behind by before events are dropped for it
+const subscriberBuffer = 64
+
+// Tracker the location tracker shared by the UI and everything else that wants to know where characters are.
+var Tracker = NewLocationTracker()
+
+// LocationTracker merges the location sources into one stream and remembers where each character was last seen.
+type LocationTracker struct {
+	events  chan LocationEvent
+	sources map[string]LocationSource
+	// subscribers each with the WaitGroup counting its unhandled events, nil when it isn't tracked
+	subscribers map[chan LocationEvent]*sync.WaitGroup
+	locations   map[string]LocationEvent
+	latest      LocationEvent
+	lock        sync.RWMutex
+}
+
+// NewLocationTracker creates a tracker with no sources.
+func NewLocationTracker() *LocationTracker {
+	tracker := &LocationTracker{
+		events:      make(chan LocationEvent),
+		sources:     make(map[string]LocationSource),
+		subscribers: make(map[chan LocationEvent]*sync.WaitGroup),
+		locations:   make(map[string]LocationEvent),
+	}
+	go tracker.run()
+
+	return tracker
+}
+
+// AddSource starts a source, replacing any running source with the same name.
+func (t *LocationTracker) AddSource(source LocationSource) {
+	t.RemoveSource(source.Name())
+
+	t.lock.Lock()
+	t.sources[source.Name()] = source
+	t.lock.Unlock()
+
+	source.Start(t.events)
+}
+
+// RemoveSource stops a source by name.
+func (t *LocationTracker) RemoveSource(name string) {
+	t.lock.Lock()
+	source, exists := t.sources[name]
+	delete(t.sources, name)
+	t.lock.Unlock()
+
+	if exists {
+		source.Stop()
+	}
+}
+
+// Subscribe returns a channel receiving every location event from now on.
+func (t *LocationTracker) Subscribe() <-chan LocationEvent {
+	return t.SubscribeTracked(nil)
+}
+
+// SubscribeTracked is Subscribe with each event added to pending before Locations shows it, the subscriber calls
+// Done once it has handled the event. Waiting on pending then covers events still on their way.
+func (t *LocationTracker) SubscribeTracked(pending *sync.WaitGroup) <-chan LocationEvent {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	subscriber := make(chan LocationEvent, subscriberBuffer)
+	t.subscribers[subscriber] = pending
+
+	return subscriber
+}
+
+// Unsubscribe stops and closes a channel returned by Subscribe.
+func (t *LocationTracker) Unsubscribe(subscription <-chan LocationEvent) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for subscriber := range t.subscribers {
+		if subscriber == subscription {
+			delete(t.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+}
+
+// Locations returns the last event seen for every character.
+func (t *LocationTracker) Locations() map[string]LocationEvent {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	locations := make(map[string]LocationEvent)
+	for character, event := range t.locations {
+		locations[character] = event
+	}
+	return locations
+}
+
+// Latest returns the most recent event from any source.
+func (t *LocationTracker) Latest() LocationEvent {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return t.latest
+}
+
+func (t *LocationTracker) run() {
+	for event := range t.events {
+		t.lock.Lock()
+		t.locations[event.Character] = event
+		t.latest = event
+		for subscriber, pending := range t.subscribers {
+			if pending != nil {
+				pending.Add(1)
+			}
+			select {
+			case subscriber <- event:
+			default:
+				if pending != nil {
+					pending.Done()
+				}
+			}
+		}
+		t.lock.Unlock()
+	}
+}
+
+// ESILocationSource polls the logged in character's location from ESI.
+type ESILocationSource struct {
+	stop chan struct{}
+	lock sync.Mutex
+}
+
+func NewESILocationSource() *ESILocationSource {
+	return &ESILocationSource{}
+}
+
+func (s *ESILocationSource) Name() string {
+	return SourceESI
+}
+
+// Start polls ESI while logged in, only sending an event when the system changes.
+func (s *ESILocationSource) Start(events chan<- LocationEvent) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.stop != nil {
+		return
+	}
+	s.stop = make(chan struct{})
+
+	go func(stop chan struct{}) {
+		oldSolarSystemID := ""
+		ticker := time.NewTicker(esiPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+			if len(api.Tokens.AccessToken) == 0 {
+				continue
+			}
+			solarSystemID, err := api.GetLocationId(api.Tokens.AccessToken, api.Character.CharacterID)
+			if err != nil || solarSystemID == oldSolarSystemID {
+				continue
+			}
+			oldSolarSystemID = solarSystemID
+			events <- LocationEvent{
+				Character:     api.Character.CharacterName,
+				SolarSystemID: solarSystemID,
+				Time:          time.Now(),
+				Source:        SourceESI,
+			}
+		}
+	}(s.stop)
+}
+
+func (s *ESILocationSource) Stop() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+}
+
+// ManualLocationSource systems typed in by the user.
+type ManualLocationSource struct {
+	events chan<- LocationEvent
+	lock   sync.Mutex
+}
+
+func NewManualLocationSource() *ManualLocationSource {
+	return &ManualLocationSource{}
+}
+
+func (s *ManualLocationSource) Name() string {
+	return SourceManual
+}
+
+func (s *ManualLocationSource) Start(events chan<- LocationEvent) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.events = events
+}
+
+func (s *ManualLocationSource) Stop() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.events = nil
+}
+
+// Submit sends a manually entered system, an empty ID means the system name wasn't found.
+func (s *ManualLocationSource) Submit(solarSystemID string) {
+	s.lock.Lock()
+	events := s.events
+	s.lock.Unlock()
+	if events == nil {
+		return
+	}
+
+	events <- LocationEvent{
+		Character:     SourceManual,
+		SolarSystemID: solarSystemID,
+		Time:          time.Now(),
+		Source:        SourceManual,
+	}
+}
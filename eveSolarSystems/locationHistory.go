@@ -0,0 +1,359 @@
+package eveSolarSystems
+
+import (
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	bolt "go.etcd.io/bbolt"
+	"io"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// LocationMove a character arriving in a system, with how far it was from the system they were in before.
+type LocationMove struct {
+	LocationEvent
+	System SolarSystem `json:"system"`
+	Region string      `json:"region"`
+	// From the system the character was in before, empty for their first recorded move
+	From string `json:"from"`
+	// LightYears from the previous system
+	LightYears float64 `json:"light_years"`
+	// Gated the two systems are next to each other by stargate so the move was most likely through a gate
+	Gated bool `json:"gated"`
+	// Bridged a saved jump bridge links the two systems so the move was most likely through it
+	Bridged bool `json:"bridged"`
+	// Jumped the move was neither gated nor bridged so it is counted as a cyno jump, always false without stargate data
+	Jumped bool `json:"jumped"`
+	// FatigueMinutes the character's estimated jump fatigue right after the move, worn down by the time since
+	// their last jump
+	FatigueMinutes float64 `json:"fatigue_minutes"`
+	// CooldownMinutes the estimated jump activation cooldown the jump started, zero when the move wasn't a jump
+	CooldownMinutes float64 `json:"cooldown_minutes"`
+}
+
+const (
+	// locationHistoryBucket location events as JSON, keyed by the big endian UnixNano time then the character
+	locationHistoryBucket           string = "locationHistory"
+	locationHistorySetting          string = "locationHistory"
+	locationHistoryRetentionSetting string = "locationHistoryDays"
+	// DefaultLocationHistoryDays how long moves are kept until another retention is saved
+	DefaultLocationHistoryDays int = 30
+	// minJumpFatigueMinutes a jump multiplies the fatigue the character already has, but at least this much
+	minJumpFatigueMinutes float64 = 10
+	// maxJumpFatigueMinutes and maxJumpCooldownMinutes are the most fatigue and cooldown jumps can build up
+	maxJumpFatigueMinutes  float64 = 300
+	maxJumpCooldownMinutes float64 = 30
+)
+
+// lastRecorded the system each character was last recorded in, so polling the same system isn't saved again
+var lastRecorded = make(map[string]string)
+var lastRecordedLock sync.Mutex
+var startLocationHistoryOnce sync.Once
+
+// IsLocationHistoryEnabled true once location history was turned on, it is off until then.
+func IsLocationHistoryEnabled() bool {
+	return GetSetting(locationHistorySetting) == "true"
+}
+
+// SaveLocationHistoryEnabled turns recording location history on or off, what was recorded is kept.
+func SaveLocationHistoryEnabled(enabled bool) error {
+	return SaveSetting(locationHistorySetting, fmt.Sprint(enabled))
+}
+
+// GetLocationHistoryDays how many days of location history are kept.
+func GetLocationHistoryDays() int {
+	if days, err := strconv.Atoi(GetSetting(locationHistoryRetentionSetting)); err == nil && days > 0 {
+		return days
+	}
+	return DefaultLocationHistoryDays
+}
+
+// SaveLocationHistoryDays saves how many days of location history are kept and drops anything older.
+func SaveLocationHistoryDays(days int) error {
+	if days < 1 {
+		return fmt.Errorf("location history has to be kept at least 1 day")
+	}
+	if err := SaveSetting(locationHistoryRetentionSetting, strconv.Itoa(days)); err != nil {
+		return err
+	}
+	return PruneLocationHistory()
+}
+
+// StartLocationHistory records the tracked characters' moves while location history is turned on, only the first
+// call does anything. Manual system checks aren't moves so they aren't recorded.
+func StartLocationHistory() {
+	startLocationHistoryOnce.Do(func() {
+		if err := PruneLocationHistory(); err != nil {
+			log.Println("Error pruning location history:", err)
+		}
+		// Start from where each character was last recorded so reopening the app doesn't record a move
+		lastRecordedLock.Lock()
+		for _, event := range readLocationHistory() {
+			lastRecorded[event.Character] = event.SolarSystemID
+		}
+		lastRecordedLock.Unlock()
+		go func(locations <-chan LocationEvent) {
+			for event := range locations {
+				if event.Source == SourceManual || !IsLocationHistoryEnabled() {
+					continue
+				}
+				if err := RecordLocation(event); err != nil {
+					log.Println("Error recording location history:", err)
+				}
+			}
+		}(Tracker.Subscribe())
+	})
+}
+
+// RecordLocation saves a location event when the character is in a different system from the last one recorded
+// for them, and drops moves older than the retention.
+func RecordLocation(event LocationEvent) error {
+	lastRecordedLock.Lock()
+	defer lastRecordedLock.Unlock()
+	if lastRecorded[event.Character] == event.SolarSystemID {
+		return nil
+	}
+
+	value, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	cutoff := locationHistoryCutoff()
+
+	db, err := openDB()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(locationHistoryBucket))
+		if err != nil {
+			return err
+		}
+		if err := pruneLocationBucket(bucket, cutoff); err != nil {
+			return err
+		}
+		return bucket.Put(locationHistoryKey(event), value)
+	})
+	if err == nil {
+		lastRecorded[event.Character] = event.SolarSystemID
+	}
+	return err
+}
+
+// GetLocationHistory every recorded move since a time, oldest first. A blank character gets every character's.
+// Moves that weren't through a gate or a saved jump bridge are counted as cyno jumps to estimate jump fatigue,
+// without hull bonuses, so a jump freighter's or black ops' fatigue is overestimated and a clone jump is counted.
+func GetLocationHistory(character string, since time.Time) []LocationMove {
+	regions := loadRegionIndex()
+	gates := loadStargateGraph()
+	hasStargates := len(gates) > 0
+	// bridges the systems each system has a saved jump bridge to, by name as bridges are saved by name
+	bridges := make(map[string][]string)
+	for _, bridge := range GetJumpBridges() {
+		bridges[bridge.From] = append(bridges[bridge.From], bridge.To)
+		bridges[bridge.To] = append(bridges[bridge.To], bridge.From)
+	}
+
+	// Distances and fatigue are from each character's previous moves, even ones from before since
+	previous := make(map[string]SolarSystem)
+	lastJump := make(map[string]LocationMove)
+	moves := []LocationMove{}
+	for _, event := range readLocationHistory() {
+		if character != "" && event.Character != character {
+			continue
+		}
+		solarSystem, _ := FindSystemByID(event.SolarSystemID)
+		move := LocationMove{LocationEvent: event, System: solarSystem, Region: regions[solarSystem.ID]}
+		if from, found := previous[event.Character]; found {
+			move.From = from.Name
+			move.LightYears = ToLightYears(Distance3D(from.Coordinates, solarSystem.Coordinates))
+			move.Gated = containsString(gates[from.ID], solarSystem.ID)
+			move.Bridged = containsString(bridges[from.Name], solarSystem.Name)
+			move.Jumped = hasStargates && !move.Gated && !move.Bridged
+		}
+		fatigue, _ := JumpFatigueAt(lastJump[event.Character], event.Time)
+		move.FatigueMinutes = fatigue
+		if move.Jumped {
+			move.CooldownMinutes = math.Min(math.Max(fatigue/10, 1+move.LightYears), maxJumpCooldownMinutes)
+			move.FatigueMinutes = math.Min(math.Max(fatigue, minJumpFatigueMinutes)*(1+move.LightYears), maxJumpFatigueMinutes)
+			lastJump[event.Character] = move
+		}
+		previous[event.Character] = solarSystem
+		if !event.Time.Before(since) {
+			moves = append(moves, move)
+		}
+	}
+	return moves
+}
+
+// JumpFatigueAt the fatigue and cooldown minutes left at a time from those the last jump started, both wear off a
+// minute a minute.
+func JumpFatigueAt(lastJump LocationMove, at time.Time) (float64, float64) {
+	elapsed := at.Sub(lastJump.Time).Minutes()
+	return math.Max(lastJump.FatigueMinutes-elapsed, 0), math.Max(lastJump.CooldownMinutes-elapsed, 0)
+}
+
+// FormatMinutes fatigue or cooldown minutes for display as hours and minutes, blank when there is none left.
+func FormatMinutes(minutes float64) string {
+	rounded := int(math.Ceil(minutes))
+	if rounded <= 0 {
+		return ""
+	}
+	if rounded < 60 {
+		return fmt.Sprintf("%dm", rounded)
+	}
+	return fmt.Sprintf("%dh%02dm", rounded/60, rounded%60)
+}
+
+// GetLocationHistoryCharacters every character with recorded moves, sorted by name.
+func GetLocationHistoryCharacters() []string {
+	var characters []string
+	for _, move := range GetLocationHistory("", time.Time{}) {
+		if !containsString(characters, move.Character) {
+			characters = append(characters, move.Character)
+		}
+	}
+	sort.Strings(characters)
+	return characters
+}
+
+// PruneLocationHistory drops moves older than the retention.
+func PruneLocationHistory() error {
+	cutoff := locationHistoryCutoff()
+
+	db, err := openDB()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	return db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(locationHistoryBucket))
+		if bucket == nil {
+			return nil
+		}
+		return pruneLocationBucket(bucket, cutoff)
+	})
+}
+
+// ClearLocationHistory drops every recorded move.
+func ClearLocationHistory() error {
+	db, err := openDB()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket([]byte(locationHistoryBucket)) != nil {
+			if err := tx.DeleteBucket([]byte(locationHistoryBucket)); err != nil {
+				return err
+			}
+		}
+		_, err := tx.CreateBucket([]byte(locationHistoryBucket))
+		return err
+	})
+	if err == nil {
+		lastRecordedLock.Lock()
+		lastRecorded = make(map[string]string)
+		lastRecordedLock.Unlock()
+	}
+	return err
+}
+
+// WriteLocationHistoryCSV writes one row per move, oldest first.
+func WriteLocationHistoryCSV(w io.Writer, moves []LocationMove) error {
+	csvWriter := csv.NewWriter(w)
+	if err := csvWriter.Write([]string{"Time", "Character", "System", "Sec", "Region", "From", "LY", "Gated", "Bridged", "Jumped", "Fatigue minutes", "Cooldown minutes", "Source"}); err != nil {
+		return err
+	}
+	for _, move := range moves {
+		var lightYears string
+		if move.From != "" {
+			lightYears = strconv.FormatFloat(move.LightYears, 'f', 2, 64)
+		}
+		err := csvWriter.Write([]string{
+			move.Time.UTC().Format(time.RFC3339),
+			move.Character,
+			move.System.Name,
+			strconv.FormatFloat(DisplaySecurity(move.System.Sec), 'f', 1, 64),
+			move.Region,
+			move.From,
+			lightYears,
+			strconv.FormatBool(move.Gated),
+			strconv.FormatBool(move.Bridged),
+			strconv.FormatBool(move.Jumped),
+			strconv.FormatFloat(move.FatigueMinutes, 'f', 1, 64),
+			strconv.FormatFloat(move.CooldownMinutes, 'f', 1, 64),
+			move.Source,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// readLocationHistory every recorded location event, oldest first.
+func readLocationHistory() []LocationEvent {
+	db, err := openDB()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	var events []LocationEvent
+	err = db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(locationHistoryBucket))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(key, value []byte) error {
+			var event LocationEvent
+			if err := json.Unmarshal(value, &event); err != nil {
+				return err
+			}
+			events = append(events, event)
+			return nil
+		})
+	})
+
+	if err != nil {
+		log.Fatal(err)
+	}
+	return events
+}
+
+// locationHistoryCutoff moves recorded before this are dropped.
+func locationHistoryCutoff() time.Time {
+	return time.Now().AddDate(0, 0, -GetLocationHistoryDays())
+}
+
+// locationHistoryKey sorts by time, the character keeps two characters moving at the same moment apart.
+func locationHistoryKey(event LocationEvent) []byte {
+	key := binary.BigEndian.AppendUint64(nil, uint64(event.Time.UnixNano()))
+	return append(key, event.Character...)
+}
+
+// pruneLocationBucket deletes the moves before the cutoff, they are the first keys as keys start with the time.
+func pruneLocationBucket(bucket *bolt.Bucket, cutoff time.Time) error {
+	cursor := bucket.Cursor()
+	for key, _ := cursor.First(); key != nil && len(key) >= 8; key, _ = cursor.First() {
+		if int64(binary.BigEndian.Uint64(key[:8])) >= cutoff.UnixNano() {
+			return nil
+		}
+		if err := cursor.Delete(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package eveSolarSystems
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestLocationHistoryFatigue(t *testing.T) {
+	if _, err := AddJumpBridge(JumpBridge{From: "Nova Prime", To: "Sierra"}); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now().Add(-3 * time.Hour).Truncate(time.Minute)
+	moves := []struct {
+		minutes  int
+		system   string
+		via      string
+		fatigue  float64
+		cooldown float64
+	}{
+		{0, "Alpha", "", 0, 0},
+		{1, "Nova", "gate", 0, 0},
+		// 5.39 LY with no fatigue yet, so the 10 minute minimum grows by 1 + LY
+		{2, "Zulu", "jump", 10 * (1 + math.Sqrt(29)), 1 + math.Sqrt(29)},
+		// 30 minutes later the fatigue left is multiplied, the cooldown is still 1 + LY
+		{32, "Alpha", "jump", (10*(1+math.Sqrt(29)) - 30) * 7, 7},
+		// The fatigue left sets a cooldown longer than 1 + LY, and the fatigue hits the cap
+		{62, "Foxtrot", "jump", maxJumpFatigueMinutes, ((10*(1+math.Sqrt(29))-30)*7 - 30) / 10},
+		// Gating wears fatigue off without adding to it
+		{72, "Zulu", "gate", maxJumpFatigueMinutes - 10, 0},
+		{80, "Nova Prime", "jump", maxJumpFatigueMinutes, maxJumpCooldownMinutes},
+		// Saved jump bridges don't add fatigue either
+		{90, "Sierra", "bridge", maxJumpFatigueMinutes - 10, 0},
+	}
+	for _, move := range moves {
+		err := RecordLocation(LocationEvent{
+			Character:     "Fatigue Tester",
+			SolarSystemID: findTestSystem(t, move.system).ID,
+			Time:          start.Add(time.Duration(move.minutes) * time.Minute),
+			Source:        SourceManual,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	history := GetLocationHistory("Fatigue Tester", time.Time{})
+	if len(history) != len(moves) {
+		t.Fatalf("got %d moves, want %d", len(history), len(moves))
+	}
+	for i, want := range moves {
+		got := history[i]
+		var via string
+		switch {
+		case got.Gated:
+			via = "gate"
+		case got.Bridged:
+			via = "bridge"
+		case got.Jumped:
+			via = "jump"
+		}
+		if via != want.via {
+			t.Errorf("%s: via %q, want %q", want.system, via, want.via)
+		}
+		if math.Abs(got.FatigueMinutes-want.fatigue) > 0.01 || math.Abs(got.CooldownMinutes-want.cooldown) > 0.01 {
+			t.Errorf("%s: fatigue %.2f cooldown %.2f, want %.2f and %.2f", want.system, got.FatigueMinutes, got.CooldownMinutes, want.fatigue, want.cooldown)
+		}
+	}
+
+	last := history[len(history)-1]
+	fatigue, cooldown := JumpFatigueAt(history[len(history)-2], last.Time)
+	// The bridge was taken 10 minutes into the last jump's cooldown
+	if math.Abs(fatigue-last.FatigueMinutes) > 0.01 || math.Abs(cooldown-(maxJumpCooldownMinutes-10)) > 0.01 {
+		t.Errorf("fatigue left %.2f cooldown %.2f, want %.2f and %.2f", fatigue, cooldown, last.FatigueMinutes, maxJumpCooldownMinutes-10)
+	}
+}
@@ -0,0 +1,100 @@
+package eveSolarSystems
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PortalType a hull that can open a jump portal for other ships, a Titan bridge or a Black Ops covert bridge.
+type PortalType struct {
+	Name string `json:"name"`
+	// Profile the range profile the portal reaches, the same as the hull's own jump range
+	Profile string `json:"profile"`
+	// Hulls the ships the portal can move
+	Hulls []string `json:"hulls"`
+	// DefaultIsotopesPerMillionKg rough fuel per light year per million kg bridged, used until another rate is saved
+	DefaultIsotopesPerMillionKg float64 `json:"default_isotopes_per_million_kg"`
+}
+
+// PortalLanding a staging a portal can land a fleet on and the fuel it costs.
+type PortalLanding struct {
+	StagingInRange
+	Isotopes int `json:"isotopes"`
+}
+
+// portalFuelSetting prefix of the saved fuel rate of each portal type
+const portalFuelSetting string = "portalFuel."
+
+// PortalTypes every portal type in the order they are shown.
+var PortalTypes = []PortalType{
+	{
+		Name:                        "Titan",
+		Profile:                     "Supers",
+		Hulls:                       []string{"Subcapital ships"},
+		DefaultIsotopesPerMillionKg: 1,
+	},
+	{
+		Name:    "Blops",
+		Profile: "Blops",
+		Hulls: []string{
+			"Stealth Bombers", "Covert Ops", "Force Recons", "Blockade Runners",
+			"Strategic Cruisers with a covert subsystem",
+		},
+		DefaultIsotopesPerMillionKg: 15,
+	},
+}
+
+// FindPortalType matches a portal type by name, case insensitive.
+func FindPortalType(name string) (PortalType, bool) {
+	for _, portalType := range PortalTypes {
+		if strings.EqualFold(portalType.Name, strings.TrimSpace(name)) {
+			return portalType, true
+		}
+	}
+	return PortalType{}, false
+}
+
+// GetPortalFuelRate the saved isotopes per light year per million kg for a portal type, or its default.
+func GetPortalFuelRate(portalType PortalType) float64 {
+	if rate, err := strconv.ParseFloat(GetSetting(portalFuelSetting+portalType.Name), 64); err == nil && rate > 0 {
+		return rate
+	}
+	return portalType.DefaultIsotopesPerMillionKg
+}
+
+// SavePortalFuelRate saves the isotopes per light year per million kg a portal type uses, e.g. to match the
+// pilot's skills.
+func SavePortalFuelRate(portalType PortalType, rate float64) error {
+	if rate <= 0 {
+		return fmt.Errorf("fuel rate has to be more than 0")
+	}
+	return SaveSetting(portalFuelSetting+portalType.Name, strconv.FormatFloat(rate, 'f', -1, 64))
+}
+
+// GetPortalLandings the stagings a portal opened in a system can land a fleet of massKg on, nearest first.
+// Highsec stagings are left out as there is no cyno to bridge to.
+func GetPortalLandings(from SolarSystem, portalType PortalType, massKg float64) []PortalLanding {
+	rate := GetPortalFuelRate(portalType)
+	landings := []PortalLanding{}
+	for _, staging := range GetStagingsInRange(from.Coordinates, ShipRanges[portalType.Profile]) {
+		if IsHighsec(staging.System) || staging.System.ID == from.ID {
+			continue
+		}
+		landings = append(landings, PortalLanding{
+			StagingInRange: staging,
+			Isotopes:       portalIsotopes(rate, massKg, staging.LightYears),
+		})
+	}
+	sort.Slice(landings, func(i, j int) bool {
+		return landings[i].LightYears < landings[j].LightYears
+	})
+	return landings
+}
+
+// portalIsotopes the isotopes to bridge a fleet of massKg a distance.
+func portalIsotopes(isotopesPerMillionKg float64, massKg float64, lightYears float64) int {
+	return int(math.Ceil(isotopesPerMillionKg * massKg / 1e6 * lightYears))
+}
@@ -0,0 +1,87 @@
+package eveSolarSystems
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// SystemInReach a system every target can be jumped to from.
+type SystemInReach struct {
+	System SolarSystem `json:"system"`
+	Region string      `json:"region"`
+	Sov    string      `json:"sov"`
+	// LightYears the distance to the furthest target, the one that decides whether the system is in range
+	LightYears float64 `json:"light_years"`
+}
+
+// ReachFilter narrows the systems in reach, blank fields match everything.
+type ReachFilter struct {
+	Region string `json:"region"`
+	// Sov matches part of the sovereignty holder's name, case insensitive
+	Sov string `json:"sov"`
+}
+
+// GetSystemsInReach the New Eden systems within jumpRange meters of every target, nearest first. With one target
+// that is everywhere a fleet could jump into it from, with more it is where a fleet could threaten all of them.
+func GetSystemsInReach(targets []SolarSystem, jumpRange float64, filter ReachFilter) ([]SystemInReach, error) {
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no target systems")
+	}
+	// Loaded before the loop as both open the DB the first time
+	regions := loadRegionIndex()
+	sov := getSovereigntyHolders()
+	if filter.Region != "" {
+		var err error
+		if regions, err = EnsureRegionIndex(); err != nil {
+			return nil, err
+		}
+		knownRegion := false
+		for _, region := range GetRegionNames() {
+			knownRegion = knownRegion || strings.EqualFold(region, strings.TrimSpace(filter.Region))
+		}
+		if !knownRegion {
+			return nil, fmt.Errorf("unknown region %q", filter.Region)
+		}
+	}
+
+	maxSquared := jumpRange * jumpRange
+	var systems []SystemInReach
+	for _, solarSystem := range systemsWithinRange(targets[0], jumpRange) {
+		if !strings.HasPrefix(solarSystem.ID, newEdenIDPrefix) {
+			continue
+		}
+		if filter.Region != "" && !strings.EqualFold(regions[solarSystem.ID], strings.TrimSpace(filter.Region)) {
+			continue
+		}
+		holder := sov[solarSystem.ID]
+		if filter.Sov != "" && !strings.Contains(strings.ToLower(holder), strings.ToLower(strings.TrimSpace(filter.Sov))) {
+			continue
+		}
+
+		var furthest float64
+		inReach := true
+		for _, target := range targets {
+			squared := squaredDistance(solarSystem.Coordinates, target.Coordinates)
+			if squared > maxSquared || solarSystem.ID == target.ID {
+				inReach = false
+				break
+			}
+			furthest = max(furthest, squared)
+		}
+		if inReach {
+			systems = append(systems, SystemInReach{
+				System:     solarSystem,
+				Region:     regions[solarSystem.ID],
+				Sov:        holder,
+				LightYears: ToLightYears(math.Sqrt(furthest)),
+			})
+		}
+	}
+
+	sort.Slice(systems, func(i, j int) bool {
+		return systems[i].LightYears < systems[j].LightYears
+	})
+	return systems, nil
+}
@@ -0,0 +1,184 @@
+package eveSolarSystems
+
+import (
+	"fmt"
+	"github.com/sythe7448/Eve-Sonar/api"
+	bolt "go.etcd.io/bbolt"
+	"log"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// regionsBucket solar system ID to region name, built once from ESI as regions don't change.
+const regionsBucket string = "regions"
+
+// regionWorkers how many constellations are fetched from ESI at the same time
+const regionWorkers = 10
+
+// regionIndex in memory copy of the regions bucket, nil until loaded.
+var regionIndex map[string]string
+var regionIndexLock sync.Mutex
+
+// regionBuildLock stops the app and a command fetching the regions from ESI at the same time
+var regionBuildLock sync.Mutex
+
+// GetSystemRegion the region a system is in, empty until the region index has been built.
+func GetSystemRegion(solarSystemID string) string {
+	return loadRegionIndex()[solarSystemID]
+}
+
+// GetRegionNames every region in the index, sorted.
+func GetRegionNames() []string {
+	seen := make(map[string]struct{})
+	var names []string
+	for _, region := range loadRegionIndex() {
+		if _, exists := seen[region]; !exists {
+			seen[region] = struct{}{}
+			names = append(names, region)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HasRegionIndex true once the region index has been built.
+func HasRegionIndex() bool {
+	return len(loadRegionIndex()) > 0
+}
+
+// EnsureRegionIndex fetches the regions from ESI when the index hasn't been built yet, so the region filter and
+// breakdowns work from the command line and the local API without the app having been opened first.
+func EnsureRegionIndex() (map[string]string, error) {
+	regionBuildLock.Lock()
+	defer regionBuildLock.Unlock()
+	if regions := loadRegionIndex(); len(regions) > 0 {
+		return regions, nil
+	}
+	if err := BuildRegionIndex(); err != nil {
+		return nil, fmt.Errorf("fetching regions from ESI: %w", err)
+	}
+	return loadRegionIndex(), nil
+}
+
+// BuildRegionIndex fetches every region and constellation from ESI and saves the region of each system.
+func BuildRegionIndex() error {
+	regionIDs, err := api.GetRegionIDs()
+	if err != nil {
+		return err
+	}
+
+	// Every region's constellations first, then the constellations are fetched in parallel
+	regionNames := make(map[int]string)
+	var constellationIDs []int
+	for _, regionID := range regionIDs {
+		region, err := api.GetRegion(regionID)
+		if err != nil {
+			return err
+		}
+		regionNames[region.RegionID] = region.Name
+		constellationIDs = append(constellationIDs, region.Constellations...)
+	}
+
+	index := make(map[string]string)
+	var indexLock sync.Mutex
+	var firstErr error
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < regionWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for constellationID := range jobs {
+				constellation, err := api.GetConstellation(constellationID)
+				indexLock.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				for _, systemID := range constellation.Systems {
+					index[strconv.Itoa(systemID)] = regionNames[constellation.RegionID]
+				}
+				indexLock.Unlock()
+			}
+		}()
+	}
+	for _, constellationID := range constellationIDs {
+		jobs <- constellationID
+	}
+	close(jobs)
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	if len(index) == 0 {
+		return fmt.Errorf("ESI returned no regions")
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket([]byte(regionsBucket)) != nil {
+			if err := tx.DeleteBucket([]byte(regionsBucket)); err != nil {
+				return err
+			}
+		}
+		bucket, err := tx.CreateBucket([]byte(regionsBucket))
+		if err != nil {
+			return err
+		}
+		for systemID, region := range index {
+			if err := bucket.Put([]byte(systemID), []byte(region)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	regionIndexLock.Lock()
+	regionIndex = index
+	regionIndexLock.Unlock()
+
+	return nil
+}
+
+// loadRegionIndex reads the regions bucket into memory the first time it is needed.
+func loadRegionIndex() map[string]string {
+	regionIndexLock.Lock()
+	defer regionIndexLock.Unlock()
+	if regionIndex != nil {
+		return regionIndex
+	}
+
+	db, err := openDB()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	index := make(map[string]string)
+	err = db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(regionsBucket))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(systemID, region []byte) error {
+			index[string(systemID)] = string(region)
+			return nil
+		})
+	})
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// An empty index is kept so the bucket isn't read again until it is built
+	regionIndex = index
+	return regionIndex
+}
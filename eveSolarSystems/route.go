@@ -0,0 +1,90 @@
+package eveSolarSystems
+
+import (
+	"fmt"
+)
+
+// RouteStep one hop of a route.
+type RouteStep struct {
+	From       SolarSystem `json:"from"`
+	To         SolarSystem `json:"to"`
+	LightYears float64     `json:"light_years"`
+	// Type how the step travels, always a jump for FindJumpRoute
+	Type RouteStepType `json:"type"`
+	// Bridge the jump bridge a bridge step goes through
+	Bridge *JumpBridge `json:"bridge,omitempty"`
+	// Fuel the isotopes a jump step burns, set for jumps of a planned route
+	Fuel *FuelCost `json:"fuel,omitempty"`
+}
+
+// FindJumpRoute returns the fewest jumps from one system to another using only jump drives.
+// Every system after the start has to be outside highsec.
+func FindJumpRoute(from SolarSystem, to SolarSystem, jumpRange float64) ([]RouteStep, error) {
+	if from.ID == to.ID {
+		return nil, nil
+	}
+	if IsHighsec(to) {
+		return nil, fmt.Errorf("%s is highsec and can't be jumped to", to.Name)
+	}
+
+	previous := map[string]SolarSystem{from.ID: {}}
+	queue := []SolarSystem{from}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range systemsWithinRange(current, jumpRange) {
+			if _, visited := previous[next.ID]; visited || IsHighsec(next) {
+				continue
+			}
+			previous[next.ID] = current
+			if next.ID == to.ID {
+				return buildRoute(previous, from, to), nil
+			}
+			queue = append(queue, next)
+		}
+	}
+
+	return nil, fmt.Errorf("no jump route from %s to %s", from.Name, to.Name)
+}
+
+// buildRoute walks the previous system map back from the destination.
+func buildRoute(previous map[string]SolarSystem, from SolarSystem, to SolarSystem) []RouteStep {
+	var route []RouteStep
+	for current := to; current.ID != from.ID; current = previous[current.ID] {
+		hop := previous[current.ID]
+		route = append([]RouteStep{{
+			From:       hop,
+			To:         current,
+			LightYears: ToLightYears(Distance3D(hop.Coordinates, current.Coordinates)),
+			Type:       RouteStepJump,
+		}}, route...)
+	}
+	return route
+}
+
+// systemsWithinRange every known system within jumpRange meters of a system, not including itself.
+func systemsWithinRange(solarSystem SolarSystem, jumpRange float64) []SolarSystem {
+	return withinRange(solarSystem, GetAllSolarSystems(), jumpRange)
+}
+
+// withinRange the systems within jumpRange meters of a system, not including itself.
+func withinRange(solarSystem SolarSystem, systems []SolarSystem, jumpRange float64) []SolarSystem {
+	var inRange []SolarSystem
+	maxSquared := jumpRange * jumpRange
+	for _, other := range systems {
+		if other.ID != solarSystem.ID && squaredDistance(solarSystem.Coordinates, other.Coordinates) <= maxSquared {
+			inRange = append(inRange, other)
+		}
+	}
+	return inRange
+}
+
+// squaredDistance Distance3D without the square root or big.Float, for loops over every system.
+// Plain float64 subtraction is already correctly rounded so the result matches Distance3D squared.
+func squaredDistance(p1, p2 Coordinates) float64 {
+	dx := p1.X - p2.X
+	dy := p1.Y - p2.Y
+	dz := p1.Z - p2.Z
+
+	return dx*dx + dy*dy + dz*dz
+}
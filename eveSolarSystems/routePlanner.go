@@ -0,0 +1,275 @@
+package eveSolarSystems
+
+import (
+	"container/heap"
+	"fmt"
+	"strings"
+)
+
+// RouteStepType how a route step travels.
+type RouteStepType string
+
+const (
+	RouteStepGate   RouteStepType = "gate"
+	RouteStepBridge RouteStepType = "bridge"
+	RouteStepJump   RouteStepType = "jump"
+)
+
+// RoutePreference what a planned route keeps as low as it can.
+type RoutePreference string
+
+const (
+	// RouteFastest the least estimated travel time
+	RouteFastest RoutePreference = "fast"
+	// RouteSafest avoids gating through lowsec and nullsec and landing cyno jumps outside friendly systems
+	RouteSafest RoutePreference = "safe"
+	// RouteLeastFatigue avoids cyno jumps, and takes short ones when it has to
+	RouteLeastFatigue RoutePreference = "fatigue"
+)
+
+// RoutePreferences every route preference in the order they are shown.
+var RoutePreferences = []RoutePreference{RouteFastest, RouteSafest, RouteLeastFatigue}
+
+// Rough seconds each kind of step takes, including aligning, warping to the gate, bridge or cyno and the jump itself
+const (
+	gateStepSeconds   float64 = 60
+	bridgeStepSeconds float64 = 90
+	jumpStepSeconds   float64 = 180
+	// safeLowsecSeconds and safeNullsecSeconds are added to gating into lowsec or nullsec by safe routes
+	safeLowsecSeconds  float64 = 300
+	safeNullsecSeconds float64 = 600
+	// safeLandingSeconds is added to bridges and cyno jumps by safe routes, they land somewhere known but are still seen
+	safeLandingSeconds float64 = 120
+	// safeUnfriendlyLandingSeconds is added to cyno jumps by safe routes that land outside the friendly systems, the
+	// fleet sits on an open cyno in space rather than docking or tethering
+	safeUnfriendlyLandingSeconds float64 = 600
+	// fatigueSecondsPerLightYear is added to cyno jumps by least fatigue routes, fatigue grows with each light year jumped
+	fatigueSecondsPerLightYear float64 = 600
+)
+
+// RouteOptions what a planned route can use.
+type RouteOptions struct {
+	// Profile the range profile cyno jumps are made with, no cyno jumps when blank
+	Profile     string
+	Gates       bool
+	JumpBridges []JumpBridge
+	Prefer      RoutePreference
+	// Hull the hull jumping for the fuel estimate, the saved hull for the profile when blank
+	Hull string
+	// Friendly systems with structures a fleet can dock or tether at, safe routes land cyno jumps in them
+	Friendly []SolarSystem
+}
+
+// RoutePlan a planned route, with totals for each kind of step.
+type RoutePlan struct {
+	Steps      []RouteStep `json:"steps"`
+	Gates      int         `json:"gates"`
+	Bridges    int         `json:"bridges"`
+	Jumps      int         `json:"jumps"`
+	LightYears float64     `json:"light_years"`
+	Minutes    float64     `json:"minutes"`
+	// Fuel the isotopes every jump burns together, nil without jumps
+	Fuel *FuelCost `json:"fuel,omitempty"`
+}
+
+// FindRoutePreference matches a route preference by name, case insensitive.
+func FindRoutePreference(name string) (RoutePreference, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, preference := range RoutePreferences {
+		if name == string(preference) {
+			return preference, true
+		}
+	}
+	return "", false
+}
+
+// PlanRoute the cheapest route from one system to another mixing gates, jump bridges and cyno jumps.
+// Cyno jumps can't land in highsec, and Capitals and Supers can't gate into highsec either.
+func PlanRoute(from SolarSystem, to SolarSystem, options RouteOptions) (RoutePlan, error) {
+	if options.Prefer == "" {
+		options.Prefer = RouteFastest
+	}
+	var jumpRange float64
+	if options.Profile != "" {
+		profileName, found := FindShipRange(options.Profile)
+		if !found {
+			return RoutePlan{}, fmt.Errorf("unknown range profile %q", options.Profile)
+		}
+		options.Profile = profileName
+		jumpRange = ShipRanges[profileName]
+	}
+	fuelSettings := GetFuelSettings()
+	hull := fuelSettings.Hull(options.Profile)
+	if options.Hull != "" && options.Profile != "" {
+		var found bool
+		if hull, found = FindJumpHull(options.Hull); !found || hull.Profile != options.Profile {
+			return RoutePlan{}, fmt.Errorf("%q isn't a %s hull", options.Hull, options.Profile)
+		}
+	}
+	if options.Gates && !HasStargates() {
+		return RoutePlan{}, errNoStargates
+	}
+	if from.ID == to.ID {
+		return RoutePlan{}, nil
+	}
+	bridges, err := jumpBridgeGraph(options.JumpBridges)
+	if err != nil {
+		return RoutePlan{}, err
+	}
+	var gates map[string][]string
+	if options.Gates {
+		gates = loadStargateGraph()
+	}
+	gatesIntoHighsec := options.Profile != "Capitals" && options.Profile != "Supers"
+	friendly := make(map[string]bool)
+	for _, solarSystem := range options.Friendly {
+		friendly[solarSystem.ID] = true
+	}
+
+	costs := map[string]float64{from.ID: 0}
+	previous := map[string]RouteStep{}
+	queue := &routeQueue{{systemID: from.ID}}
+	for queue.Len() > 0 {
+		current := heap.Pop(queue).(routeQueueItem)
+		if current.cost > costs[current.systemID] {
+			continue
+		}
+		if current.systemID == to.ID {
+			plan := buildRoutePlan(previous, from, to)
+			addRouteFuel(&plan, hull, fuelSettings)
+			return plan, nil
+		}
+		currentSystem, _ := FindSystemByID(current.systemID)
+
+		var steps []RouteStep
+		for _, id := range gates[current.systemID] {
+			if next, found := FindSystemByID(id); found && (gatesIntoHighsec || !IsHighsec(next)) {
+				steps = append(steps, newRouteStep(currentSystem, next, RouteStepGate))
+			}
+		}
+		steps = append(steps, bridges[current.systemID]...)
+		if jumpRange > 0 {
+			for _, next := range systemsWithinRange(currentSystem, jumpRange) {
+				if !IsHighsec(next) && strings.HasPrefix(next.ID, newEdenIDPrefix) {
+					steps = append(steps, newRouteStep(currentSystem, next, RouteStepJump))
+				}
+			}
+		}
+
+		for _, step := range steps {
+			cost := current.cost + routeStepCost(step, options.Prefer, friendly)
+			if known, visited := costs[step.To.ID]; visited && known <= cost {
+				continue
+			}
+			costs[step.To.ID] = cost
+			previous[step.To.ID] = step
+			heap.Push(queue, routeQueueItem{systemID: step.To.ID, cost: cost})
+		}
+	}
+
+	return RoutePlan{}, fmt.Errorf("no route from %s to %s", from.Name, to.Name)
+}
+
+// RouteStepSeconds a rough estimate of how long a step takes.
+func RouteStepSeconds(step RouteStep) float64 {
+	switch step.Type {
+	case RouteStepGate:
+		return gateStepSeconds
+	case RouteStepBridge:
+		return bridgeStepSeconds
+	}
+	return jumpStepSeconds
+}
+
+// routeStepCost the cost of a step for a route preference, in seconds so the preferences can be mixed with time.
+func routeStepCost(step RouteStep, prefer RoutePreference, friendly map[string]bool) float64 {
+	cost := RouteStepSeconds(step)
+	switch prefer {
+	case RouteSafest:
+		switch {
+		case step.Type == RouteStepJump && !friendly[step.To.ID]:
+			cost += safeLandingSeconds + safeUnfriendlyLandingSeconds
+		case step.Type != RouteStepGate:
+			cost += safeLandingSeconds
+		case DisplaySecurity(step.To.Sec) <= 0:
+			cost += safeNullsecSeconds
+		case !IsHighsec(step.To):
+			cost += safeLowsecSeconds
+		}
+	case RouteLeastFatigue:
+		if step.Type == RouteStepJump {
+			cost += (1 + step.LightYears) * fatigueSecondsPerLightYear
+		}
+	}
+	return cost
+}
+
+// buildRoutePlan walks the previous steps back from the destination and adds up the totals.
+func buildRoutePlan(previous map[string]RouteStep, from SolarSystem, to SolarSystem) RoutePlan {
+	var plan RoutePlan
+	for id := to.ID; id != from.ID; id = previous[id].From.ID {
+		plan.Steps = append([]RouteStep{previous[id]}, plan.Steps...)
+	}
+	for _, step := range plan.Steps {
+		switch step.Type {
+		case RouteStepGate:
+			plan.Gates++
+		case RouteStepBridge:
+			plan.Bridges++
+		case RouteStepJump:
+			plan.Jumps++
+			plan.LightYears += step.LightYears
+		}
+		plan.Minutes += RouteStepSeconds(step) / 60
+	}
+	return plan
+}
+
+func newRouteStep(from SolarSystem, to SolarSystem, stepType RouteStepType) RouteStep {
+	return RouteStep{
+		From:       from,
+		To:         to,
+		LightYears: ToLightYears(Distance3D(from.Coordinates, to.Coordinates)),
+		Type:       stepType,
+	}
+}
+
+// jumpBridgeGraph a step for each way across each jump bridge, by the system ID the step starts from.
+func jumpBridgeGraph(jumpBridges []JumpBridge) (map[string][]RouteStep, error) {
+	graph := make(map[string][]RouteStep)
+	for i := range jumpBridges {
+		bridge := &jumpBridges[i]
+		from, found := FindSystemByName(bridge.From)
+		if !found {
+			return nil, fmt.Errorf("unknown jump bridge system %q", bridge.From)
+		}
+		to, found := FindSystemByName(bridge.To)
+		if !found {
+			return nil, fmt.Errorf("unknown jump bridge system %q", bridge.To)
+		}
+		there, back := newRouteStep(from, to, RouteStepBridge), newRouteStep(to, from, RouteStepBridge)
+		there.Bridge, back.Bridge = bridge, bridge
+		graph[from.ID] = append(graph[from.ID], there)
+		graph[to.ID] = append(graph[to.ID], back)
+	}
+	return graph, nil
+}
+
+type routeQueueItem struct {
+	systemID string
+	cost     float64
+}
+
+// routeQueue a heap of systems to visit, cheapest first.
+type routeQueue []routeQueueItem
+
+func (q routeQueue) Len() int           { return len(q) }
+func (q routeQueue) Less(i, j int) bool { return q[i].cost < q[j].cost }
+func (q routeQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *routeQueue) Push(item any)     { *q = append(*q, item.(routeQueueItem)) }
+func (q *routeQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,41 @@
+package eveSolarSystems
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPlanRoutePreferences(t *testing.T) {
+	alpha, zulu := findTestSystem(t, "Alpha"), findTestSystem(t, "Zulu")
+	friendly := []SolarSystem{findTestSystem(t, "Foxtrot")}
+	tests := []struct {
+		name     string
+		prefer   RoutePreference
+		friendly []SolarSystem
+		want     string
+	}{
+		// One cyno jump is the quickest
+		{"fast", RouteFastest, friendly, "jump Zulu"},
+		// Landing on friendly Foxtrot and gating into lowsec beats an open cyno in Zulu or gating through nullsec
+		{"safe", RouteSafest, friendly, "jump Foxtrot, gate Zulu"},
+		// Without friendly systems every landing is as exposed, so the single jump is the least gating
+		{"safe without friendly systems", RouteSafest, nil, "jump Zulu"},
+		// Any cyno jump adds fatigue, so gates all the way
+		{"fatigue", RouteLeastFatigue, friendly, "gate Nova, gate November, gate Niner, gate Zulu"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			plan, err := PlanRoute(alpha, zulu, RouteOptions{Profile: "Capitals", Gates: true, Prefer: test.prefer, Friendly: test.friendly})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var steps []string
+			for _, step := range plan.Steps {
+				steps = append(steps, string(step.Type)+" "+step.To.Name)
+			}
+			if got := strings.Join(steps, ", "); got != test.want {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package eveSolarSystems
+
+import (
+	"github.com/sythe7448/Eve-Sonar/api"
+	bolt "go.etcd.io/bbolt"
+	"strconv"
+	"time"
+)
+
+// SovereigntyChange a tracked staging whose sovereignty holder changed since the last refresh.
+type SovereigntyChange struct {
+	System    string
+	OldHolder string
+	NewHolder string
+	Time      time.Time
+}
+
+// RefreshSovereignty pulls the sovereignty map from ESI and saves the holder name of every claimed system.
+// Returns the staging systems whose holder changed and when ESI will have new data.
+func RefreshSovereignty() ([]SovereigntyChange, time.Time, error) {
+	sovereigntyMap, expires, err := api.GetSovereigntyMap()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	holderIDs := make(map[string]int)
+	for _, sov := range sovereigntyMap {
+		holderID := sov.AllianceID
+		if holderID == 0 {
+			holderID = sov.FactionID
+		}
+		if holderID == 0 {
+			holderID = sov.CorporationID
+		}
+		if holderID != 0 {
+			holderIDs[strconv.Itoa(sov.SystemID)] = holderID
+		}
+	}
+
+	names, err := resolveNames(holderIDs)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	stagingIDs := make(map[string]string)
+	for _, system := range GetStagingSystemNames() {
+		solarSystem := GetSystemByName(system)
+		if len(solarSystem.ID) > 0 {
+			stagingIDs[solarSystem.ID] = solarSystem.Name
+		}
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer db.Close()
+
+	var changes []SovereigntyChange
+	err = db.Update(func(tx *bolt.Tx) error {
+		// Only report changes once there is a previous map to compare against
+		if bucket := tx.Bucket([]byte(sovereigntyBucket)); bucket != nil {
+			if key, _ := bucket.Cursor().First(); key != nil {
+				for id, name := range stagingIDs {
+					oldHolder := string(bucket.Get([]byte(id)))
+					newHolder := names[holderIDs[id]]
+					if oldHolder != newHolder {
+						changes = append(changes, SovereigntyChange{
+							System:    name,
+							OldHolder: oldHolder,
+							NewHolder: newHolder,
+							Time:      time.Now(),
+						})
+					}
+				}
+			}
+			if err := tx.DeleteBucket([]byte(sovereigntyBucket)); err != nil {
+				return err
+			}
+		}
+
+		bucket, err := tx.CreateBucketIfNotExists([]byte(sovereigntyBucket))
+		if err != nil {
+			return err
+		}
+		for id, holderID := range holderIDs {
+			if err := bucket.Put([]byte(id), []byte(names[holderID])); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	return changes, expires, nil
+}
+
+// GetSovereigntyHolder returns the cached sovereignty holder name for a system, empty if unclaimed or unknown.
+func GetSovereigntyHolder(systemID string) string {
+	db, err := openDB()
+	if err != nil {
+		return ""
+	}
+	defer db.Close()
+
+	var holder string
+	_ = db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(sovereigntyBucket))
+		if bucket != nil {
+			holder = string(bucket.Get([]byte(systemID)))
+		}
+		return nil
+	})
+
+	return holder
+}
+
+// getSovereigntyHolders every cached sovereignty holder name by system ID, for lookups over many systems.
+func getSovereigntyHolders() map[string]string {
+	holders := make(map[string]string)
+	db, err := openDB()
+	if err != nil {
+		return holders
+	}
+	defer db.Close()
+
+	_ = db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(sovereigntyBucket))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(systemID, holder []byte) error {
+			holders[string(systemID)] = string(holder)
+			return nil
+		})
+	})
+
+	return holders
+}
+
+// resolveNames maps holder ids to names, only asking ESI for ids that aren't cached in the names bucket.
+func resolveNames(holderIDs map[string]int) (map[int]string, error) {
+	db, err := openDB()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	names := make(map[int]string)
+	var missing []int
+	err = db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(namesBucket))
+		for _, id := range holderIDs {
+			if _, seen := names[id]; seen {
+				continue
+			}
+			if name := bucket.Get([]byte(strconv.Itoa(id))); name != nil {
+				names[id] = string(name)
+				continue
+			}
+			names[id] = ""
+			missing = append(missing, id)
+		}
+		return nil
+	})
+	if err != nil || len(missing) == 0 {
+		return names, err
+	}
+
+	resolved, err := api.GetNames(missing)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(namesBucket))
+		for _, name := range resolved {
+			names[name.ID] = name.Name
+			if err := bucket.Put([]byte(strconv.Itoa(name.ID)), []byte(name.Name)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return names, err
+}
@@ -0,0 +1,311 @@
+//go:build gui
+
+package eveSolarSystems
+
+import (
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"sort"
+	"strings"
+)
+
+// stagingEditorRow a staging being edited, Error says why it can't be saved.
+type stagingEditorRow struct {
+	System string
+	Note   string
+	Error  string
+}
+
+// StagingEditor edits one staging list as rows, nothing is saved until Save is called.
+type StagingEditor struct {
+	List *widget.List
+	// OnChanged is called after every edit, undo, paste, load and save
+	OnChanged func()
+	list      string
+	rows      []stagingEditorRow
+	undo      [][]stagingEditorRow
+	lastEdit  string
+	dirty     bool
+	readOnly  bool
+	items     []*stagingEditorItem
+}
+
+// maxUndo how many edits can be undone
+const maxUndo = 100
+
+// NewStagingEditor an editor with no list loaded.
+func NewStagingEditor() *StagingEditor {
+	e := &StagingEditor{OnChanged: func() {}}
+	e.List = widget.NewList(
+		func() int {
+			return len(e.rows)
+		},
+		func() fyne.CanvasObject {
+			item := newStagingEditorItem()
+			e.items = append(e.items, item)
+			return item
+		},
+		func(id widget.ListItemID, object fyne.CanvasObject) {
+			e.updateItem(id, object.(*stagingEditorItem))
+		},
+	)
+	return e
+}
+
+// Load replaces the rows with the saved stagings of a list, dropping any unsaved edits and the undo history.
+// Subscribed lists are loaded read only.
+func (e *StagingEditor) Load(list string) {
+	e.list = list
+	e.readOnly = IsStagingListReadOnly(list)
+	e.rows = nil
+	for system, note := range GetStagingList(list) {
+		e.rows = append(e.rows, stagingEditorRow{System: system, Note: note})
+	}
+	sort.Slice(e.rows, func(i, j int) bool {
+		return e.rows[i].System < e.rows[j].System
+	})
+	e.undo, e.lastEdit, e.dirty = nil, "", false
+	e.validate()
+	e.List.Refresh()
+	e.OnChanged()
+}
+
+// Dirty true when there are edits that haven't been saved.
+func (e *StagingEditor) Dirty() bool {
+	return e.dirty
+}
+
+// ErrorCount how many rows can't be saved.
+func (e *StagingEditor) ErrorCount() int {
+	count := 0
+	for _, row := range e.rows {
+		if row.Error != "" {
+			count++
+		}
+	}
+	return count
+}
+
+// ReadOnly true when the loaded list is subscribed and can't be edited.
+func (e *StagingEditor) ReadOnly() bool {
+	return e.readOnly
+}
+
+// CanUndo true when there is an edit to undo.
+func (e *StagingEditor) CanUndo() bool {
+	return len(e.undo) > 0
+}
+
+// AddRow adds an empty row at the end.
+func (e *StagingEditor) AddRow() {
+	if e.readOnly {
+		return
+	}
+	e.pushUndo("")
+	e.rows = append(e.rows, stagingEditorRow{})
+	e.changed()
+	e.List.ScrollToBottom()
+}
+
+// DeleteRow removes a row.
+func (e *StagingEditor) DeleteRow(id int) {
+	if e.readOnly || id < 0 || id >= len(e.rows) {
+		return
+	}
+	e.pushUndo("")
+	e.rows = append(e.rows[:id:id], e.rows[id+1:]...)
+	e.changed()
+}
+
+// Undo goes back to before the last edit, typing in one field counts as one edit.
+func (e *StagingEditor) Undo() {
+	if len(e.undo) == 0 {
+		return
+	}
+	e.rows = e.undo[len(e.undo)-1]
+	e.undo = e.undo[:len(e.undo)-1]
+	e.lastEdit = ""
+	e.changed()
+}
+
+// Paste adds system:note lines as rows, replacing the note of systems already in the list,
+// and returns the lines that were rejected.
+func (e *StagingEditor) Paste(text string) []RejectedLine {
+	entries, rejected := ParseStagingText(text)
+	if e.readOnly || len(entries) == 0 {
+		return rejected
+	}
+
+	e.pushUndo("")
+	for _, entry := range entries {
+		replaced := false
+		for i := range e.rows {
+			if strings.EqualFold(strings.TrimSpace(e.rows[i].System), entry.System) {
+				e.rows[i].Note = entry.Note
+				replaced = true
+			}
+		}
+		if !replaced {
+			e.rows = append(e.rows, stagingEditorRow{System: entry.System, Note: entry.Note})
+		}
+	}
+	e.changed()
+
+	return rejected
+}
+
+// Save saves the rows to the list, blank rows are dropped. Nothing is saved while any row has an error.
+func (e *StagingEditor) Save() error {
+	e.validate()
+	if count := e.ErrorCount(); count > 0 {
+		e.List.Refresh()
+		return fmt.Errorf("fix the %d rows with errors first", count)
+	}
+
+	stagings := make(map[string]string)
+	for _, row := range e.rows {
+		if solarSystem, found := FindSystemByName(row.System); found {
+			stagings[solarSystem.Name] = strings.TrimSpace(row.Note)
+		}
+	}
+	if err := UpdateStagingList(e.list, stagings); err != nil {
+		return err
+	}
+	recheckRangesInBackground()
+
+	e.Load(e.list)
+	return nil
+}
+
+// setField updates a row as it is typed in, only the first keystroke in a field is an undo step.
+func (e *StagingEditor) setField(id int, field string, text string) {
+	if id >= len(e.rows) {
+		return
+	}
+	e.pushUndo(fmt.Sprintf("%d:%s", id, field))
+	if field == "system" {
+		e.rows[id].System = text
+	} else {
+		e.rows[id].Note = text
+	}
+	e.dirty = true
+	e.validate()
+
+	// Only the error texts are refreshed so the entry being typed in keeps its cursor
+	for _, item := range e.items {
+		if item.id >= 0 && item.id < len(e.rows) {
+			item.setError(e.rows[item.id].Error)
+		}
+	}
+	e.OnChanged()
+}
+
+func (e *StagingEditor) pushUndo(edit string) {
+	if edit != "" && edit == e.lastEdit {
+		return
+	}
+	e.lastEdit = edit
+	e.undo = append(e.undo, append([]stagingEditorRow(nil), e.rows...))
+	if len(e.undo) > maxUndo {
+		e.undo = e.undo[1:]
+	}
+}
+
+func (e *StagingEditor) changed() {
+	e.dirty = true
+	e.validate()
+	e.List.Refresh()
+	e.OnChanged()
+}
+
+// validate sets the error of every row, blank rows are fine as they are dropped on save.
+func (e *StagingEditor) validate() {
+	seen := make(map[string]int)
+	for i := range e.rows {
+		row := &e.rows[i]
+		row.Error = ""
+		if strings.TrimSpace(row.System) == "" && strings.TrimSpace(row.Note) == "" {
+			continue
+		}
+		solarSystem, err := ValidateStagingSystem(row.System)
+		if err != nil {
+			row.Error = err.Error()
+			continue
+		}
+		if firstRow, duplicate := seen[solarSystem.ID]; duplicate {
+			row.Error = fmt.Sprintf("%s is already on row %d", solarSystem.Name, firstRow+1)
+			continue
+		}
+		seen[solarSystem.ID] = i
+	}
+}
+
+func (e *StagingEditor) updateItem(id widget.ListItemID, item *stagingEditorItem) {
+	item.id = id
+	row := e.rows[id]
+
+	item.system.OnChanged = nil
+	item.note.OnChanged = nil
+	item.system.SetText(row.System)
+	item.note.SetText(row.Note)
+	item.system.OnChanged = func(text string) {
+		e.setField(item.id, "system", text)
+	}
+	item.note.OnChanged = func(text string) {
+		e.setField(item.id, "note", text)
+	}
+	item.remove.OnTapped = func() {
+		e.DeleteRow(item.id)
+	}
+	item.setError(row.Error)
+	for _, object := range []fyne.Disableable{item.system, item.note, item.remove} {
+		if e.readOnly {
+			object.Disable()
+		} else {
+			object.Enable()
+		}
+	}
+}
+
+// stagingEditorItem one row of the staging editor.
+type stagingEditorItem struct {
+	widget.BaseWidget
+	id        widget.ListItemID
+	system    *widget.Entry
+	note      *widget.Entry
+	remove    *widget.Button
+	errorText *canvas.Text
+	content   fyne.CanvasObject
+}
+
+func newStagingEditorItem() *stagingEditorItem {
+	item := &stagingEditorItem{
+		id:        -1,
+		system:    widget.NewEntry(),
+		note:      widget.NewEntry(),
+		remove:    widget.NewButtonWithIcon("", theme.DeleteIcon(), nil),
+		errorText: canvas.NewText("", theme.ErrorColor()),
+	}
+	item.system.SetPlaceHolder("System")
+	item.note.SetPlaceHolder("Owner or note")
+	item.errorText.TextSize = theme.CaptionTextSize()
+	item.content = container.NewVBox(
+		container.NewBorder(nil, nil, nil, item.remove, container.NewGridWithColumns(2, item.system, item.note)),
+		item.errorText,
+	)
+	item.ExtendBaseWidget(item)
+	return item
+}
+
+func (i *stagingEditorItem) setError(text string) {
+	i.errorText.Text = text
+	i.errorText.Refresh()
+}
+
+func (i *stagingEditorItem) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(i.content)
+}
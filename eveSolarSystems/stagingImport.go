@@ -0,0 +1,216 @@
+package eveSolarSystems
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Staging list file formats
+const (
+	StagingFormatJSON  = "json"
+	StagingFormatCSV   = "csv"
+	StagingFormatYAML  = "yaml"
+	StagingFormatNames = "names"
+)
+
+// StagingFormats every import and export format.
+var StagingFormats = []string{StagingFormatJSON, StagingFormatCSV, StagingFormatYAML, StagingFormatNames}
+
+// stagingFileEntry a staging as it is written to files, the list is the file itself.
+type stagingFileEntry struct {
+	System string `json:"system" yaml:"system"`
+	Note   string `json:"note" yaml:"note"`
+}
+
+// ImportResult what an import changed in a list.
+type ImportResult struct {
+	Added     []string `json:"added"`
+	Updated   []string `json:"updated"`
+	Unchanged []string `json:"unchanged"`
+	Removed   []string `json:"removed"`
+}
+
+// StagingFormatFromFilename picks the format from a file extension, anything unknown is read as plain names.
+func StagingFormatFromFilename(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		return StagingFormatJSON
+	case ".csv":
+		return StagingFormatCSV
+	case ".yaml", ".yml":
+		return StagingFormatYAML
+	}
+	return StagingFormatNames
+}
+
+// ExportStagingList writes a list's stagings sorted by system in one of the StagingFormats.
+func ExportStagingList(list string, format string) ([]byte, error) {
+	entries := []stagingFileEntry{}
+	for system, note := range GetStagingList(list) {
+		entries = append(entries, stagingFileEntry{System: system, Note: note})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].System < entries[j].System
+	})
+
+	switch format {
+	case StagingFormatJSON:
+		data, err := json.MarshalIndent(entries, "", "  ")
+		return append(data, '\n'), err
+	case StagingFormatYAML:
+		return yaml.Marshal(entries)
+	case StagingFormatCSV:
+		var buffer bytes.Buffer
+		writer := csv.NewWriter(&buffer)
+		writer.Write([]string{"system", "note"})
+		for _, entry := range entries {
+			writer.Write([]string{entry.System, entry.Note})
+		}
+		writer.Flush()
+		return buffer.Bytes(), writer.Error()
+	case StagingFormatNames:
+		var buffer bytes.Buffer
+		for _, entry := range entries {
+			fmt.Fprintln(&buffer, entry.System)
+		}
+		return buffer.Bytes(), nil
+	}
+	return nil, fmt.Errorf("unknown staging format %q, use %s", format, strings.Join(StagingFormats, ", "))
+}
+
+// ParseStagingFile reads stagings in one of the StagingFormats. Unknown systems and systems already
+// earlier in the file are returned as rejected, numbered by line for text formats and by entry otherwise.
+func ParseStagingFile(data []byte, format string) ([]StagingEntry, []RejectedLine, error) {
+	var fileEntries []stagingFileEntry
+	var lines []int
+	switch format {
+	case StagingFormatJSON:
+		if err := json.Unmarshal(data, &fileEntries); err != nil {
+			return nil, nil, err
+		}
+	case StagingFormatYAML:
+		if err := yaml.Unmarshal(data, &fileEntries); err != nil {
+			return nil, nil, err
+		}
+	case StagingFormatCSV:
+		reader := csv.NewReader(bytes.NewReader(data))
+		reader.FieldsPerRecord = -1
+		records, err := reader.ReadAll()
+		if err != nil {
+			return nil, nil, err
+		}
+		for i, record := range records {
+			if i == 0 && strings.EqualFold(strings.TrimSpace(record[0]), "system") {
+				continue
+			}
+			entry := stagingFileEntry{System: record[0]}
+			if len(record) > 1 {
+				entry.Note = record[1]
+			}
+			fileEntries = append(fileEntries, entry)
+			lines = append(lines, i+1)
+		}
+	case StagingFormatNames:
+		// One system per line, copies from a spreadsheet keep the next column as the note
+		for i, line := range strings.Split(string(data), "\n") {
+			system, note, _ := strings.Cut(strings.TrimSpace(line), "\t")
+			if strings.TrimSpace(system) == "" {
+				continue
+			}
+			fileEntries = append(fileEntries, stagingFileEntry{System: system, Note: note})
+			lines = append(lines, i+1)
+		}
+	default:
+		return nil, nil, fmt.Errorf("unknown staging format %q, use %s", format, strings.Join(StagingFormats, ", "))
+	}
+
+	var entries []StagingEntry
+	var rejected []RejectedLine
+	seen := make(map[string]int)
+	for i, fileEntry := range fileEntries {
+		line := i + 1
+		if lines != nil {
+			line = lines[i]
+		}
+		text := strings.TrimSpace(fileEntry.System)
+		if note := strings.TrimSpace(fileEntry.Note); note != "" {
+			text += ":" + note
+		}
+
+		solarSystem, err := ValidateStagingSystem(fileEntry.System)
+		if err != nil {
+			rejected = append(rejected, RejectedLine{Line: line, Text: text, Reason: err.Error()})
+			continue
+		}
+		if firstLine, duplicate := seen[solarSystem.ID]; duplicate {
+			rejected = append(rejected, RejectedLine{Line: line, Text: text, Reason: fmt.Sprintf("%s is already on line %d", solarSystem.Name, firstLine)})
+			continue
+		}
+		seen[solarSystem.ID] = line
+		entries = append(entries, StagingEntry{System: solarSystem.Name, Note: strings.TrimSpace(fileEntry.Note)})
+	}
+	return entries, rejected, nil
+}
+
+// ImportStagings saves imported stagings to a list. Merging keeps stagings that aren't imported and
+// replaces the note of ones that are, replacing makes the list exactly what was imported.
+func ImportStagings(list string, entries []StagingEntry, replace bool) (ImportResult, error) {
+	list = strings.TrimSpace(list)
+	if existing, found := FindStagingList(list); found {
+		list = existing
+	}
+
+	stagings, result := MergeStagings(GetStagingList(list), entries, replace)
+	if err := UpdateStagingList(list, stagings); err != nil {
+		return ImportResult{}, err
+	}
+	recheckRangesInBackground()
+
+	return result, nil
+}
+
+// MergeStagings applies imported stagings to a list's system:note map and reports what changed.
+func MergeStagings(existing map[string]string, entries []StagingEntry, replace bool) (map[string]string, ImportResult) {
+	result := ImportResult{}
+	stagings := make(map[string]string)
+	existingByName := make(map[string]string)
+	for system, note := range existing {
+		existingByName[strings.ToLower(system)] = system
+		if !replace {
+			stagings[system] = note
+		}
+	}
+
+	imported := make(map[string]struct{})
+	for _, entry := range entries {
+		key := strings.ToLower(entry.System)
+		imported[key] = struct{}{}
+		system, exists := existingByName[key]
+		switch {
+		case !exists:
+			result.Added = append(result.Added, entry.System)
+		case existing[system] == entry.Note:
+			result.Unchanged = append(result.Unchanged, entry.System)
+		default:
+			result.Updated = append(result.Updated, entry.System)
+		}
+		delete(stagings, system)
+		stagings[entry.System] = entry.Note
+	}
+	if replace {
+		for key, system := range existingByName {
+			if _, kept := imported[key]; !kept {
+				result.Removed = append(result.Removed, system)
+			}
+		}
+		sort.Strings(result.Removed)
+	}
+
+	return stagings, result
+}
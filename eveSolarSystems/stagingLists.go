@@ -0,0 +1,267 @@
+package eveSolarSystems
+
+import (
+	"fmt"
+	bolt "go.etcd.io/bbolt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// StagingEntry a saved staging system and the list it is saved in.
+type StagingEntry struct {
+	List   string `json:"list"`
+	System string `json:"system"`
+	Note   string `json:"note"`
+}
+
+// DefaultStagingList the list stagings go in when no list is picked, kept in the original staging bucket.
+const DefaultStagingList = "Default"
+
+// stagingListsBucket holds one nested bucket of system:note per named list.
+const stagingListsBucket string = "stagingLists"
+
+// GetStagingListNames every staging list, the default list first then by name.
+func GetStagingListNames() []string {
+	db, err := openDB()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	var names []string
+	err = db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(stagingListsBucket))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(name, value []byte) error {
+			// nested buckets have no value
+			if value == nil {
+				names = append(names, string(name))
+			}
+			return nil
+		})
+	})
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	sort.Strings(names)
+	return append([]string{DefaultStagingList}, names...)
+}
+
+// FindStagingList matches a list by name, case insensitive, a blank name is the default list.
+func FindStagingList(name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return DefaultStagingList, true
+	}
+	for _, list := range GetStagingListNames() {
+		if strings.EqualFold(list, name) {
+			return list, true
+		}
+	}
+	return "", false
+}
+
+// GetStagingList the stagings saved in a list keyed by system name, empty for an unknown list.
+func GetStagingList(list string) map[string]string {
+	if list == DefaultStagingList {
+		return GetStagingSystems()
+	}
+
+	db, err := openDB()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	stagings := make(map[string]string)
+	err = db.View(func(tx *bolt.Tx) error {
+		bucket := getStagingListBucket(tx, list)
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(system, note []byte) error {
+			stagings[string(system)] = string(note)
+			return nil
+		})
+	})
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return stagings
+}
+
+// UpdateStagingList replaces every staging in a list, creating the list if it doesn't exist.
+// Subscribed lists are read only, they only change when synced.
+func UpdateStagingList(list string, stagings map[string]string) error {
+	if IsStagingListReadOnly(list) {
+		return fmt.Errorf("%w: %s is subscribed to %s", ErrReadOnlyStagingList, list, GetSubscription(list).Source)
+	}
+	return saveStagingList(list, stagings)
+}
+
+// saveStagingList replaces every staging in a list without checking if it is read only.
+func saveStagingList(list string, stagings map[string]string) error {
+	if list == DefaultStagingList {
+		return UpdateStagingSystems(stagings)
+	}
+	if err := validateStagingListName(list); err != nil {
+		return err
+	}
+
+	db, err := openDB()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	return db.Update(func(tx *bolt.Tx) error {
+		lists, err := tx.CreateBucketIfNotExists([]byte(stagingListsBucket))
+		if err != nil {
+			return err
+		}
+		if lists.Bucket([]byte(list)) != nil {
+			if err := lists.DeleteBucket([]byte(list)); err != nil {
+				return err
+			}
+		}
+		bucket, err := lists.CreateBucket([]byte(list))
+		if err != nil {
+			return err
+		}
+		for system, note := range stagings {
+			if err := bucket.Put([]byte(system), []byte(note)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CreateStagingList adds an empty list.
+func CreateStagingList(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if err := validateStagingListName(name); err != nil {
+		return "", err
+	}
+	if existing, found := FindStagingList(name); found {
+		return "", fmt.Errorf("staging list %q already exists", existing)
+	}
+	if err := saveStagingList(name, nil); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// DeleteStagingList removes a list and its stagings, the default list can only be emptied.
+func DeleteStagingList(name string) error {
+	list, found := FindStagingList(name)
+	if !found {
+		return fmt.Errorf("unknown staging list %q", name)
+	}
+	if list == DefaultStagingList {
+		return fmt.Errorf("the %s list can't be deleted", DefaultStagingList)
+	}
+
+	if IsStagingListReadOnly(list) {
+		if err := Unsubscribe(list); err != nil {
+			return err
+		}
+	}
+	if err := SetListMuted(list, false); err != nil {
+		return err
+	}
+
+	db, err := openDB()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(stagingListsBucket)).DeleteBucket([]byte(list))
+	})
+	if err != nil {
+		return err
+	}
+	recheckRangesInBackground()
+
+	return nil
+}
+
+// GetAllStagings every staging in every list, sorted by list then system.
+func GetAllStagings() []StagingEntry {
+	var entries []StagingEntry
+	for _, list := range GetStagingListNames() {
+		for system, note := range GetStagingList(list) {
+			entries = append(entries, StagingEntry{List: list, System: system, Note: note})
+		}
+	}
+	sortStagingEntries(entries)
+	return entries
+}
+
+// GetStagingSystemNames the distinct staging systems across every list.
+func GetStagingSystemNames() []string {
+	seen := make(map[string]struct{})
+	var names []string
+	for _, entry := range GetAllStagings() {
+		if _, exists := seen[strings.ToLower(entry.System)]; !exists {
+			seen[strings.ToLower(entry.System)] = struct{}{}
+			names = append(names, entry.System)
+		}
+	}
+	return names
+}
+
+func sortStagingEntries(entries []StagingEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].List != entries[j].List {
+			return entries[i].List == DefaultStagingList || (entries[j].List != DefaultStagingList && entries[i].List < entries[j].List)
+		}
+		return entries[i].System < entries[j].System
+	})
+}
+
+// forEachStagingList calls fn with the bucket of every staging list, the default list first.
+func forEachStagingList(tx *bolt.Tx, fn func(list string, bucket *bolt.Bucket) error) error {
+	if bucket := tx.Bucket([]byte(stagingSystemsBucket)); bucket != nil {
+		if err := fn(DefaultStagingList, bucket); err != nil {
+			return err
+		}
+	}
+	lists := tx.Bucket([]byte(stagingListsBucket))
+	if lists == nil {
+		return nil
+	}
+	return lists.ForEach(func(name, value []byte) error {
+		if value != nil {
+			return nil
+		}
+		return fn(string(name), lists.Bucket(name))
+	})
+}
+
+func getStagingListBucket(tx *bolt.Tx, list string) *bolt.Bucket {
+	lists := tx.Bucket([]byte(stagingListsBucket))
+	if lists == nil {
+		return nil
+	}
+	return lists.Bucket([]byte(list))
+}
+
+func validateStagingListName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("staging list name is blank")
+	}
+	if strings.EqualFold(name, DefaultStagingList) && name != DefaultStagingList {
+		return fmt.Errorf("staging list %q is reserved", name)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package eveSolarSystems
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// StagingDistance how far apart two stagings are.
+type StagingDistance struct {
+	LightYears float64 `json:"light_years"`
+	// GateJumps the shortest stargate route, NoGateRoute when there isn't one or no stargate data
+	GateJumps int `json:"gate_jumps"`
+	// Profiles the range profiles that can jump from one staging straight to the other
+	Profiles []string `json:"profiles"`
+}
+
+// StagingPair two stagings within a range profile of each other.
+type StagingPair struct {
+	From       string  `json:"from"`
+	To         string  `json:"to"`
+	LightYears float64 `json:"light_years"`
+}
+
+// StagingMatrix the distance between every pair of stagings in a list.
+type StagingMatrix struct {
+	List    string        `json:"list"`
+	Systems []SolarSystem `json:"systems"`
+	// Distances by the index of each staging in Systems, both ways so Distances[i][j] is Distances[j][i]
+	Distances [][]StagingDistance `json:"distances"`
+}
+
+// GetStagingMatrix the light years and gate jumps between every pair of stagings in a list, sorted by name.
+func GetStagingMatrix(list string) (StagingMatrix, error) {
+	listName, found := FindStagingList(list)
+	if !found {
+		return StagingMatrix{}, fmt.Errorf("unknown staging list %q", list)
+	}
+	matrix := StagingMatrix{List: listName, Systems: []SolarSystem{}, Distances: [][]StagingDistance{}}
+	for system := range GetStagingList(listName) {
+		if solarSystem, found := FindSystemByName(system); found {
+			matrix.Systems = append(matrix.Systems, solarSystem)
+		}
+	}
+	sort.Slice(matrix.Systems, func(i, j int) bool {
+		return matrix.Systems[i].Name < matrix.Systems[j].Name
+	})
+
+	hasStargates := HasStargates()
+	for _, from := range matrix.Systems {
+		var gates map[string]int
+		if hasStargates {
+			gates = gateDistances(from.ID, -1)
+		}
+		row := make([]StagingDistance, len(matrix.Systems))
+		for j, to := range matrix.Systems {
+			distance := StagingDistance{
+				LightYears: ToLightYears(Distance3D(from.Coordinates, to.Coordinates)),
+				GateJumps:  NoGateRoute,
+				Profiles:   []string{},
+			}
+			if jumps, found := gates[to.ID]; found {
+				distance.GateJumps = jumps
+			}
+			if from.ID != to.ID {
+				for _, rangeName := range ShipRangeNames {
+					if ToLightYears(ShipRanges[rangeName]) >= distance.LightYears {
+						distance.Profiles = append(distance.Profiles, rangeName)
+					}
+				}
+			}
+			row[j] = distance
+		}
+		matrix.Distances = append(matrix.Distances, row)
+	}
+	return matrix, nil
+}
+
+// PairsWithin every pair of stagings a range profile can jump between, each pair once, nearest first.
+func (m StagingMatrix) PairsWithin(profile string) []StagingPair {
+	pairs := []StagingPair{}
+	for i := range m.Systems {
+		for j := i + 1; j < len(m.Systems); j++ {
+			if containsString(m.Distances[i][j].Profiles, profile) {
+				pairs = append(pairs, StagingPair{From: m.Systems[i].Name, To: m.Systems[j].Name, LightYears: m.Distances[i][j].LightYears})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i].LightYears < pairs[j].LightYears
+	})
+	return pairs
+}
+
+// FormatStagingDistance a matrix cell for export, e.g. "5.01 LY, 7 gates - Blops, Industry", blank for a staging
+// and itself.
+func FormatStagingDistance(distance StagingDistance) string {
+	if distance.LightYears == 0 {
+		return ""
+	}
+	text := FormatDistance(distance.LightYears, distance.GateJumps)
+	if len(distance.Profiles) > 0 {
+		text += " - " + strings.Join(distance.Profiles, ", ")
+	}
+	return text
+}
+
+// WriteStagingMatrixCSV writes the matrix with a row and a column for each staging, each cell naming the range
+// profiles that can jump between the two.
+func WriteStagingMatrixCSV(w io.Writer, matrix StagingMatrix) error {
+	csvWriter := csv.NewWriter(w)
+	header := []string{"System"}
+	for _, solarSystem := range matrix.Systems {
+		header = append(header, solarSystem.Name)
+	}
+	if err := csvWriter.Write(header); err != nil {
+		return err
+	}
+	for i, solarSystem := range matrix.Systems {
+		row := []string{solarSystem.Name}
+		for _, distance := range matrix.Distances[i] {
+			row = append(row, FormatStagingDistance(distance))
+		}
+		if err := csvWriter.Write(row); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
@@ -0,0 +1,333 @@
+//go:build gui
+
+package eveSolarSystems
+
+import (
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"image/color"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// stagingColumn a column of the results table and how its cells are read and compared.
+type stagingColumn struct {
+	title string
+	width float32
+	text  func(row stagingRow) string
+	less  func(a, b StagingInRange) bool
+}
+
+// stagingRow a staging in the results table, or a range profile heading when staging is nil.
+type stagingRow struct {
+	profile string
+	count   int
+	staging *StagingInRange
+}
+
+// StagingTable the stagings in range shown as a table, grouped by range profile and sortable by column.
+type StagingTable struct {
+	Table      *widget.Table
+	results    []RangeResult
+	rows       []stagingRow
+	sortColumn int
+	descending bool
+	grouped    bool
+	lock       sync.Mutex
+}
+
+// noSortColumn keeps the order from the activity sort option
+const noSortColumn = -1
+
+// securityColors the colors the game uses for each rounded security status, 1.0 down to 0.0 and below.
+var securityColors = []color.NRGBA{
+	{R: 0x2f, G: 0xef, B: 0xef, A: 0xff},
+	{R: 0x48, G: 0xf0, B: 0xc0, A: 0xff},
+	{R: 0x00, G: 0xef, B: 0x47, A: 0xff},
+	{R: 0x00, G: 0xf0, B: 0x00, A: 0xff},
+	{R: 0x8f, G: 0xef, B: 0x2f, A: 0xff},
+	{R: 0xef, G: 0xef, B: 0x00, A: 0xff},
+	{R: 0xd7, G: 0x77, B: 0x00, A: 0xff},
+	{R: 0xf0, G: 0x60, B: 0x00, A: 0xff},
+	{R: 0xf0, G: 0x48, B: 0x00, A: 0xff},
+	{R: 0xd7, G: 0x30, B: 0x00, A: 0xff},
+	{R: 0xf0, G: 0x00, B: 0x00, A: 0xff},
+}
+
+var stagingColumns = []stagingColumn{
+	{
+		title: "System",
+		width: 130,
+		text:  func(row stagingRow) string { return row.staging.System.Name },
+		less:  func(a, b StagingInRange) bool { return a.System.Name < b.System.Name },
+	},
+	{
+		title: "Sec",
+		width: 50,
+		text:  func(row stagingRow) string { return fmt.Sprintf("%.1f", DisplaySecurity(row.staging.System.Sec)) },
+		less:  func(a, b StagingInRange) bool { return a.System.Sec < b.System.Sec },
+	},
+	{
+		title: "Region",
+		width: 110,
+		text:  func(row stagingRow) string { return row.staging.Region },
+		less:  func(a, b StagingInRange) bool { return a.Region < b.Region },
+	},
+	{
+		title: "Owner",
+		width: 110,
+		text:  func(row stagingRow) string { return row.staging.Owner },
+		less:  func(a, b StagingInRange) bool { return strings.ToLower(a.Owner) < strings.ToLower(b.Owner) },
+	},
+	{
+		title: "List",
+		width: 80,
+		text:  func(row stagingRow) string { return row.staging.List },
+		less:  func(a, b StagingInRange) bool { return a.List < b.List },
+	},
+	{
+		title: "LY",
+		width: 55,
+		text:  func(row stagingRow) string { return fmt.Sprintf("%.2f", row.staging.LightYears) },
+		less:  func(a, b StagingInRange) bool { return a.LightYears < b.LightYears },
+	},
+	{
+		title: "Gates",
+		width: 55,
+		text:  func(row stagingRow) string { return FormatGateJumps(row.staging.GateJumps) },
+		less: func(a, b StagingInRange) bool {
+			// Systems without a gate route sort after every reachable one
+			return uint(a.GateJumps) < uint(b.GateJumps)
+		},
+	},
+	{
+		title: "Fuel",
+		width: 80,
+		text:  func(row stagingRow) string { return formatStagingFuel(*row.staging) },
+		less:  func(a, b StagingInRange) bool { return stagingIsotopes(a) < stagingIsotopes(b) },
+	},
+	{
+		title: "Ranges",
+		width: 170,
+		text:  func(row stagingRow) string { return strings.Join(stagingRanges(*row.staging), ", ") },
+		less:  func(a, b StagingInRange) bool { return len(stagingRanges(a)) < len(stagingRanges(b)) },
+	},
+	{
+		title: "Sov",
+		width: 110,
+		text:  func(row stagingRow) string { return row.staging.Sov },
+		less:  func(a, b StagingInRange) bool { return a.Sov < b.Sov },
+	},
+	{
+		title: "Kills",
+		width: 50,
+		text: func(row stagingRow) string {
+			return strconv.Itoa(row.staging.Activity.ShipKills + row.staging.Activity.PodKills)
+		},
+		less: func(a, b StagingInRange) bool {
+			return a.Activity.ShipKills+a.Activity.PodKills < b.Activity.ShipKills+b.Activity.PodKills
+		},
+	},
+	{
+		title: "Jumps",
+		width: 55,
+		text:  func(row stagingRow) string { return strconv.Itoa(row.staging.Activity.Jumps) },
+		less:  func(a, b StagingInRange) bool { return a.Activity.Jumps < b.Activity.Jumps },
+	},
+}
+
+// NewStagingTable an empty results table, grouped by range profile.
+func NewStagingTable() *StagingTable {
+	t := &StagingTable{sortColumn: noSortColumn, grouped: true}
+	t.Table = widget.NewTable(t.size, t.createCell, t.updateCell)
+	for i, column := range stagingColumns {
+		t.Table.SetColumnWidth(i, column.width)
+	}
+	// The first row holds the column titles, tapping one sorts by it and tapping it again reverses the order
+	t.Table.OnSelected = func(id widget.TableCellID) {
+		t.Table.Unselect(id)
+		if id.Row == 0 {
+			t.SortBy(id.Col)
+		}
+	}
+	return t
+}
+
+// SetResults replaces the stagings shown.
+func (t *StagingTable) SetResults(results []RangeResult) {
+	t.lock.Lock()
+	t.results = results
+	t.buildRows()
+	t.lock.Unlock()
+
+	t.Table.Refresh()
+}
+
+// SetGrouped switches between one group per range profile and one row per staging.
+func (t *StagingTable) SetGrouped(grouped bool) {
+	t.lock.Lock()
+	t.grouped = grouped
+	t.buildRows()
+	t.lock.Unlock()
+
+	t.Table.Refresh()
+}
+
+// SortBy sorts by a column, reversing the order when it is already sorted by it.
+func (t *StagingTable) SortBy(column int) {
+	t.lock.Lock()
+	if t.sortColumn == column {
+		t.descending = !t.descending
+	} else {
+		t.sortColumn, t.descending = column, false
+	}
+	t.buildRows()
+	t.lock.Unlock()
+
+	t.Table.Refresh()
+}
+
+// ResetSort goes back to the order from the activity sort option.
+func (t *StagingTable) ResetSort() {
+	t.lock.Lock()
+	t.sortColumn, t.descending = noSortColumn, false
+	t.buildRows()
+	t.lock.Unlock()
+
+	t.Table.Refresh()
+}
+
+// buildRows flattens the results into rows, the lock must be held.
+func (t *StagingTable) buildRows() {
+	t.rows = nil
+	if t.grouped {
+		for _, result := range t.results {
+			t.rows = append(t.rows, stagingRow{profile: result.Profile, count: len(result.Stagings)})
+			t.rows = append(t.rows, t.sortRows(result.Stagings)...)
+		}
+		return
+	}
+
+	// Ungrouped each staging is shown once, the Ranges column says which ranges it is in
+	seen := make(map[string]struct{})
+	var stagings []StagingInRange
+	for _, result := range t.results {
+		for _, staging := range result.Stagings {
+			key := staging.List + "/" + staging.System.ID
+			if _, exists := seen[key]; !exists {
+				seen[key] = struct{}{}
+				stagings = append(stagings, staging)
+			}
+		}
+	}
+	t.rows = t.sortRows(stagings)
+}
+
+func (t *StagingTable) sortRows(stagings []StagingInRange) []stagingRow {
+	rows := make([]stagingRow, len(stagings))
+	for i := range stagings {
+		rows[i] = stagingRow{staging: &stagings[i]}
+	}
+	if t.sortColumn == noSortColumn {
+		return rows
+	}
+
+	less := stagingColumns[t.sortColumn].less
+	sort.SliceStable(rows, func(i, j int) bool {
+		if t.descending {
+			return less(*rows[j].staging, *rows[i].staging)
+		}
+		return less(*rows[i].staging, *rows[j].staging)
+	})
+	return rows
+}
+
+func (t *StagingTable) size() (int, int) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return len(t.rows) + 1, len(stagingColumns)
+}
+
+func (t *StagingTable) createCell() fyne.CanvasObject {
+	return container.NewPadded(canvas.NewText("", theme.ForegroundColor()))
+}
+
+func (t *StagingTable) updateCell(id widget.TableCellID, cell fyne.CanvasObject) {
+	text := cell.(*fyne.Container).Objects[0].(*canvas.Text)
+	text.Text, text.Color, text.TextStyle = "", theme.ForegroundColor(), fyne.TextStyle{}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	switch {
+	case id.Row == 0:
+		text.Text = stagingColumns[id.Col].title
+		if id.Col == t.sortColumn && t.descending {
+			text.Text += " ▼"
+		} else if id.Col == t.sortColumn {
+			text.Text += " ▲"
+		}
+		text.TextStyle.Bold = true
+	case id.Row-1 < len(t.rows):
+		row := t.rows[id.Row-1]
+		if row.staging == nil {
+			// Range profile heading across the first column
+			if id.Col == 0 {
+				text.Text = fmt.Sprintf("%s range (%d)", row.profile, row.count)
+				text.TextStyle.Bold = true
+			}
+			break
+		}
+		text.Text = stagingColumns[id.Col].text(row)
+		if stagingColumns[id.Col].title == "Sec" {
+			text.Color = SecurityColor(row.staging.System.Sec)
+		}
+	}
+	text.Refresh()
+}
+
+// SecurityColor the color the game shows a security status in.
+func SecurityColor(sec float64) color.Color {
+	index := int(math.Round((1 - DisplaySecurity(sec)) * 10))
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(securityColors) {
+		index = len(securityColors) - 1
+	}
+	return securityColors[index]
+}
+
+// stagingRanges the range profiles a staging is within.
+func stagingRanges(staging StagingInRange) []string {
+	var ranges []string
+	for _, rangeName := range ShipRangeNames {
+		if ToLightYears(ShipRanges[rangeName]) >= staging.LightYears {
+			ranges = append(ranges, rangeName)
+		}
+	}
+	return ranges
+}
+
+// formatStagingFuel the isotopes to jump to a staging, blank when it wasn't worked out.
+func formatStagingFuel(staging StagingInRange) string {
+	if staging.Fuel == nil {
+		return ""
+	}
+	return strconv.Itoa(staging.Fuel.Isotopes)
+}
+
+// stagingIsotopes the isotopes to jump to a staging for sorting, stagings without fuel sort first.
+func stagingIsotopes(staging StagingInRange) int {
+	if staging.Fuel == nil {
+		return 0
+	}
+	return staging.Fuel.Isotopes
+}
@@ -0,0 +1,494 @@
+//go:build gui
+
+package eveSolarSystems
+
+import (
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strings"
+	"sync"
+)
+
+// StarMap a top-down map of New Eden, X across and Z up, with the stagings, the current system and a circle for
+// each ticked range. Circles are flat while ranges are 3D, so systems and stagings are colored by their real distance
+// and a system just inside a circle can still be out of range.
+type StarMap struct {
+	widget.BaseWidget
+	// OnSystemTapped is called with the system nearest a click
+	OnSystemTapped func(solarSystem SolarSystem)
+
+	systems  []SolarSystem
+	stagings []SolarSystem
+	// bridges the two ends of each jump bridge
+	bridges [][2]SolarSystem
+	current *SolarSystem
+	ranges  []string
+	// centerX and centerZ the map position in the middle of the widget, scale the meters per pixel
+	centerX, centerZ float64
+	scale            float64
+	// moved once panned or zoomed, until then the map is fitted to the widget whenever it resizes
+	moved         bool
+	hover         *SolarSystem
+	hoverPosition fyne.Position
+	lock          sync.Mutex
+}
+
+const (
+	// starMapTapDistance how close in pixels a click or the mouse has to be to pick a system
+	starMapTapDistance = 10
+	// starMapLabelScale meters per pixel below which every staging is labelled, further out only those in range are
+	starMapLabelScale = metersPerLightYear / 10
+	// starMapZoomStep how much one scroll or zoom button press zooms
+	starMapZoomStep = 1.25
+	// starMapMinScale and starMapMaxScale how far in and out the map zooms, in meters per pixel
+	starMapMinScale = metersPerLightYear / 1000
+	starMapMaxScale = metersPerLightYear * 2
+	// starMapOutOfRangeDim how much the dots of systems out of the longest ticked range are darkened, out of 255
+	starMapOutOfRangeDim = 170
+	// starMapLegend explains the circles when any are drawn
+	starMapLegend = "Seen from above: circles are flat but ranges are 3D, dimmed systems are out of range"
+)
+
+var (
+	starMapBackground = color.NRGBA{R: 0x0b, G: 0x0e, B: 0x14, A: 0xff}
+	stagingInRange    = color.NRGBA{R: 0xff, G: 0x8c, B: 0x1a, A: 0xff}
+	stagingOutOfRange = color.NRGBA{R: 0x80, G: 0x60, B: 0x40, A: 0xff}
+	jumpBridgeColor   = color.NRGBA{R: 0x30, G: 0xd0, B: 0xd0, A: 0xc0}
+	// rangeColors one circle color per entry of ShipRangeNames
+	rangeColors = []color.NRGBA{
+		{R: 0xb0, G: 0x60, B: 0xff, A: 0xff},
+		{R: 0xff, G: 0x50, B: 0x50, A: 0xff},
+		{R: 0x40, G: 0xa0, B: 0xff, A: 0xff},
+		{R: 0x50, G: 0xd0, B: 0x70, A: 0xff},
+	}
+)
+
+// NewStarMap a map of every New Eden system, showing all of it until it is moved.
+func NewStarMap() *StarMap {
+	m := &StarMap{}
+	for _, solarSystem := range GetAllSolarSystems() {
+		if strings.HasPrefix(solarSystem.ID, newEdenIDPrefix) {
+			m.systems = append(m.systems, solarSystem)
+		}
+	}
+	m.ExtendBaseWidget(m)
+	return m
+}
+
+// SetStagings replaces the staging markers, systems in more than one list are shown once.
+func (m *StarMap) SetStagings(entries []StagingEntry) {
+	seen := make(map[string]struct{})
+	var stagings []SolarSystem
+	for _, entry := range entries {
+		solarSystem, found := FindSystemByName(entry.System)
+		if _, exists := seen[solarSystem.ID]; found && !exists {
+			seen[solarSystem.ID] = struct{}{}
+			stagings = append(stagings, solarSystem)
+		}
+	}
+
+	m.lock.Lock()
+	m.stagings = stagings
+	m.lock.Unlock()
+	m.Refresh()
+}
+
+// SetJumpBridges replaces the jump bridge lines.
+func (m *StarMap) SetJumpBridges(jumpBridges []JumpBridge) {
+	var bridges [][2]SolarSystem
+	for _, bridge := range jumpBridges {
+		from, fromFound := FindSystemByName(bridge.From)
+		to, toFound := FindSystemByName(bridge.To)
+		if fromFound && toFound {
+			bridges = append(bridges, [2]SolarSystem{from, to})
+		}
+	}
+
+	m.lock.Lock()
+	m.bridges = bridges
+	m.lock.Unlock()
+	m.Refresh()
+}
+
+// SetCurrentSystem moves the current location marker, blank hides it.
+func (m *StarMap) SetCurrentSystem(solarSystemID string) {
+	m.lock.Lock()
+	m.current = nil
+	if solarSystem, found := FindSystemByID(solarSystemID); found {
+		m.current = &solarSystem
+	}
+	m.lock.Unlock()
+	m.Refresh()
+}
+
+// SetRanges the range profiles circled around the current system.
+func (m *StarMap) SetRanges(profiles []string) {
+	m.lock.Lock()
+	m.ranges = profiles
+	m.lock.Unlock()
+	m.Refresh()
+}
+
+// CenterOnCurrent moves the map so the current system is in the middle.
+func (m *StarMap) CenterOnCurrent() {
+	m.lock.Lock()
+	if m.current != nil {
+		m.centerX, m.centerZ = m.current.Coordinates.X, m.current.Coordinates.Z
+		m.moved = true
+	}
+	m.lock.Unlock()
+	m.Refresh()
+}
+
+// ShowAll zooms out to fit every system.
+func (m *StarMap) ShowAll() {
+	m.lock.Lock()
+	m.moved = false
+	m.fit(m.Size())
+	m.lock.Unlock()
+	m.Refresh()
+}
+
+// Zoom zooms around the middle of the map, factors above 1 zoom in.
+func (m *StarMap) Zoom(factor float64) {
+	size := m.Size()
+	m.zoomAt(factor, fyne.NewPos(size.Width/2, size.Height/2))
+}
+
+// Tapped picks the nearest system to the click.
+func (m *StarMap) Tapped(event *fyne.PointEvent) {
+	m.lock.Lock()
+	solarSystem, found := m.systemAt(event.Position)
+	m.lock.Unlock()
+	if found && m.OnSystemTapped != nil {
+		m.OnSystemTapped(solarSystem)
+	}
+}
+
+// Scrolled zooms around the mouse.
+func (m *StarMap) Scrolled(event *fyne.ScrollEvent) {
+	switch {
+	case event.Scrolled.DY > 0:
+		m.zoomAt(starMapZoomStep, event.Position)
+	case event.Scrolled.DY < 0:
+		m.zoomAt(1/starMapZoomStep, event.Position)
+	}
+}
+
+// Dragged pans the map.
+func (m *StarMap) Dragged(event *fyne.DragEvent) {
+	m.lock.Lock()
+	m.centerX -= float64(event.Dragged.DX) * m.scale
+	m.centerZ += float64(event.Dragged.DY) * m.scale
+	m.moved = true
+	m.hover = nil
+	m.lock.Unlock()
+	m.Refresh()
+}
+
+func (m *StarMap) DragEnd() {}
+
+func (m *StarMap) MouseIn(event *desktop.MouseEvent) {
+	m.MouseMoved(event)
+}
+
+// MouseMoved names the system under the mouse.
+func (m *StarMap) MouseMoved(event *desktop.MouseEvent) {
+	m.lock.Lock()
+	previous := m.hover
+	m.hover = nil
+	if solarSystem, found := m.systemAt(event.Position); found {
+		m.hover = &solarSystem
+		m.hoverPosition = event.Position
+	}
+	changed := previous != m.hover && (previous == nil || m.hover == nil || previous.ID != m.hover.ID)
+	m.lock.Unlock()
+	if changed {
+		m.Refresh()
+	}
+}
+
+func (m *StarMap) MouseOut() {
+	m.lock.Lock()
+	m.hover = nil
+	m.lock.Unlock()
+	m.Refresh()
+}
+
+func (m *StarMap) CreateRenderer() fyne.WidgetRenderer {
+	r := &starMapRenderer{starMap: m}
+	r.raster = canvas.NewRaster(r.draw)
+	return r
+}
+
+// zoomAt zooms keeping the map position under the given point still.
+func (m *StarMap) zoomAt(factor float64, position fyne.Position) {
+	m.lock.Lock()
+	if m.scale == 0 {
+		m.lock.Unlock()
+		return
+	}
+	size := m.Size()
+	x, z := m.toMap(position, size)
+	m.scale = math.Min(math.Max(m.scale/factor, starMapMinScale), starMapMaxScale)
+	m.centerX = x - float64(position.X-size.Width/2)*m.scale
+	m.centerZ = z + float64(position.Y-size.Height/2)*m.scale
+	m.moved = true
+	m.lock.Unlock()
+	m.Refresh()
+}
+
+// fit zooms to every system, the lock must be held.
+func (m *StarMap) fit(size fyne.Size) {
+	if len(m.systems) == 0 || size.Width <= 0 || size.Height <= 0 {
+		return
+	}
+	minX, maxX := math.Inf(1), math.Inf(-1)
+	minZ, maxZ := math.Inf(1), math.Inf(-1)
+	for _, solarSystem := range m.systems {
+		minX, maxX = math.Min(minX, solarSystem.Coordinates.X), math.Max(maxX, solarSystem.Coordinates.X)
+		minZ, maxZ = math.Min(minZ, solarSystem.Coordinates.Z), math.Max(maxZ, solarSystem.Coordinates.Z)
+	}
+	m.centerX, m.centerZ = (minX+maxX)/2, (minZ+maxZ)/2
+	// A little margin so the edge systems aren't cut off
+	m.scale = math.Max((maxX-minX)/float64(size.Width), (maxZ-minZ)/float64(size.Height)) * 1.05
+}
+
+// toScreen where a position is on the widget, the lock must be held.
+func (m *StarMap) toScreen(coordinates Coordinates, size fyne.Size) fyne.Position {
+	return fyne.NewPos(
+		size.Width/2+float32((coordinates.X-m.centerX)/m.scale),
+		size.Height/2-float32((coordinates.Z-m.centerZ)/m.scale),
+	)
+}
+
+// toMap the X and Z under a point of the widget, the lock must be held.
+func (m *StarMap) toMap(position fyne.Position, size fyne.Size) (float64, float64) {
+	return m.centerX + float64(position.X-size.Width/2)*m.scale, m.centerZ - float64(position.Y-size.Height/2)*m.scale
+}
+
+// systemAt the system nearest a point when one is close enough, the lock must be held.
+func (m *StarMap) systemAt(position fyne.Position) (SolarSystem, bool) {
+	size := m.Size()
+	best, bestDistance := SolarSystem{}, float32(starMapTapDistance)
+	found := false
+	for _, solarSystem := range m.systems {
+		point := m.toScreen(solarSystem.Coordinates, size)
+		distance := float32(math.Hypot(float64(point.X-position.X), float64(point.Y-position.Y)))
+		if distance <= bestDistance {
+			best, bestDistance, found = solarSystem, distance, true
+		}
+	}
+	return best, found
+}
+
+// starMapRenderer draws the systems as pixels of one raster and the markers, circles and labels as objects over it.
+type starMapRenderer struct {
+	starMap *StarMap
+	raster  *canvas.Raster
+	overlay []fyne.CanvasObject
+	lock    sync.Mutex
+}
+
+func (r *starMapRenderer) Layout(size fyne.Size) {
+	r.raster.Resize(size)
+	r.buildOverlay(size)
+}
+
+func (r *starMapRenderer) MinSize() fyne.Size {
+	return fyne.NewSize(300, 300)
+}
+
+func (r *starMapRenderer) Refresh() {
+	r.buildOverlay(r.starMap.Size())
+	r.raster.Refresh()
+	canvas.Refresh(r.starMap)
+}
+
+func (r *starMapRenderer) Objects() []fyne.CanvasObject {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return append([]fyne.CanvasObject{r.raster}, r.overlay...)
+}
+
+func (r *starMapRenderer) Destroy() {}
+
+// draw the background and a dot per system colored by security.
+func (r *starMapRenderer) draw(w, h int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), image.NewUniform(starMapBackground), image.Point{}, draw.Src)
+
+	m := r.starMap
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	size := m.Size()
+	if m.scale == 0 || size.Width <= 0 {
+		return img
+	}
+	pixelScale := float32(w) / size.Width
+	longestRange := m.longestRange()
+	for _, solarSystem := range m.systems {
+		point := m.toScreen(solarSystem.Coordinates, size)
+		x, y := int(point.X*pixelScale), int(point.Y*pixelScale)
+		if x < 0 || y < 0 || x >= w-1 || y >= h-1 {
+			continue
+		}
+		dotColor := SecurityColor(solarSystem.Sec)
+		if longestRange > 0 && Distance3D(m.current.Coordinates, solarSystem.Coordinates) > longestRange {
+			dotColor = dimColor(dotColor, starMapOutOfRangeDim)
+		}
+		img.Set(x, y, dotColor)
+		img.Set(x+1, y, dotColor)
+		img.Set(x, y+1, dotColor)
+		img.Set(x+1, y+1, dotColor)
+	}
+	return img
+}
+
+// buildOverlay positions the range circles, jump bridges, staging markers, current system and hover label.
+func (r *starMapRenderer) buildOverlay(size fyne.Size) {
+	m := r.starMap
+	m.lock.Lock()
+	if !m.moved {
+		m.fit(size)
+	}
+	var overlay []fyne.CanvasObject
+
+	// Range circles around the current system, biggest range furthest back
+	longestRange := m.longestRange()
+	if m.current != nil {
+		center := m.toScreen(m.current.Coordinates, size)
+		for i, rangeName := range ShipRangeNames {
+			if !containsString(m.ranges, rangeName) {
+				continue
+			}
+			radius := float32(ShipRanges[rangeName] / m.scale)
+			circle := canvas.NewCircle(color.Transparent)
+			circle.StrokeColor = rangeColors[i%len(rangeColors)]
+			circle.StrokeWidth = 1.5
+			circle.Move(fyne.NewPos(center.X-radius, center.Y-radius))
+			circle.Resize(fyne.NewSize(radius*2, radius*2))
+			label := starMapText(fmt.Sprintf("%s %.1f LY", rangeName, ToLightYears(ShipRanges[rangeName])), rangeColors[i%len(rangeColors)], false)
+			label.Move(fyne.NewPos(center.X-label.MinSize().Width/2, center.Y-radius-label.MinSize().Height))
+			overlay = append(overlay, circle, label)
+		}
+	}
+
+	for _, bridge := range m.bridges {
+		line := canvas.NewLine(jumpBridgeColor)
+		line.StrokeWidth = 1.5
+		line.Position1 = m.toScreen(bridge[0].Coordinates, size)
+		line.Position2 = m.toScreen(bridge[1].Coordinates, size)
+		overlay = append(overlay, line)
+	}
+
+	for _, staging := range m.stagings {
+		point := m.toScreen(staging.Coordinates, size)
+		inRange := m.current != nil && Distance3D(m.current.Coordinates, staging.Coordinates) <= longestRange
+		markerColor := stagingOutOfRange
+		if inRange {
+			markerColor = stagingInRange
+		}
+		overlay = append(overlay, starMapMarker(point, 4, markerColor))
+		if inRange || m.scale < starMapLabelScale {
+			label := starMapText(staging.Name, markerColor, false)
+			label.Move(fyne.NewPos(point.X+6, point.Y-label.MinSize().Height/2))
+			overlay = append(overlay, label)
+		}
+	}
+
+	if m.current != nil {
+		point := m.toScreen(m.current.Coordinates, size)
+		label := starMapText(m.current.Name, theme.PrimaryColor(), true)
+		label.Move(fyne.NewPos(point.X+8, point.Y-label.MinSize().Height/2))
+		overlay = append(overlay, starMapMarker(point, 6, theme.PrimaryColor()), label)
+	}
+
+	if longestRange > 0 {
+		legend := starMapText(starMapLegend, theme.ForegroundColor(), false)
+		legend.Move(fyne.NewPos(4, size.Height-legend.MinSize().Height-4))
+		overlay = append(overlay, legend)
+	}
+
+	if m.hover != nil {
+		text := fmt.Sprintf("%s %.1f", m.hover.Name, DisplaySecurity(m.hover.Sec))
+		if region := GetSystemRegion(m.hover.ID); region != "" {
+			text += " " + region
+		}
+		if m.current != nil {
+			distance := Distance3D(m.current.Coordinates, m.hover.Coordinates)
+			text += fmt.Sprintf(" %.2f LY", ToLightYears(distance))
+			// The tooltip goes by the 3D distance as the circles only show X and Z
+			var inRange []string
+			for _, rangeName := range ShipRangeNames {
+				if containsString(m.ranges, rangeName) && distance <= ShipRanges[rangeName] {
+					inRange = append(inRange, rangeName)
+				}
+			}
+			switch {
+			case len(inRange) > 0:
+				text += ", in " + strings.Join(inRange, ", ") + " range"
+			case longestRange > 0:
+				text += ", out of range"
+			}
+		}
+		label := starMapText(text, theme.ForegroundColor(), true)
+		background := canvas.NewRectangle(color.NRGBA{A: 0xc0})
+		background.Move(fyne.NewPos(m.hoverPosition.X+12, m.hoverPosition.Y+12))
+		background.Resize(label.MinSize().Add(fyne.NewSize(8, 4)))
+		label.Move(background.Position().Add(fyne.NewPos(4, 2)))
+		overlay = append(overlay, background, label)
+	}
+	m.lock.Unlock()
+
+	r.lock.Lock()
+	r.overlay = overlay
+	r.lock.Unlock()
+}
+
+// longestRange the longest ticked range in meters, 0 without a current system or ticked range, the lock must be held.
+func (m *StarMap) longestRange() float64 {
+	var longestRange float64
+	if m.current == nil {
+		return 0
+	}
+	for _, rangeName := range m.ranges {
+		longestRange = math.Max(longestRange, ShipRanges[rangeName])
+	}
+	return longestRange
+}
+
+// dimColor darkens a color towards the map background by amount out of 255.
+func dimColor(c color.Color, amount uint8) color.Color {
+	r, g, b, _ := c.RGBA()
+	dim := func(value uint32, background uint8) uint8 {
+		return uint8((int(value>>8)*int(255-amount) + int(background)*int(amount)) / 255)
+	}
+	return color.NRGBA{R: dim(r, starMapBackground.R), G: dim(g, starMapBackground.G), B: dim(b, starMapBackground.B), A: 0xff}
+}
+
+func starMapMarker(center fyne.Position, radius float32, markerColor color.Color) *canvas.Circle {
+	marker := canvas.NewCircle(markerColor)
+	marker.StrokeColor = starMapBackground
+	marker.StrokeWidth = 1
+	marker.Move(fyne.NewPos(center.X-radius, center.Y-radius))
+	marker.Resize(fyne.NewSize(radius*2, radius*2))
+	return marker
+}
+
+func starMapText(text string, textColor color.Color, bold bool) *canvas.Text {
+	label := canvas.NewText(text, textColor)
+	label.TextSize = theme.CaptionTextSize()
+	label.TextStyle.Bold = bold
+	label.Resize(label.MinSize())
+	return label
+}
@@ -0,0 +1,428 @@
+package eveSolarSystems
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/sythe7448/Eve-Sonar/api"
+	bolt "go.etcd.io/bbolt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GateDistance a system and how many gate jumps away it is.
+type GateDistance struct {
+	System SolarSystem `json:"system"`
+	Jumps  int         `json:"jumps"`
+}
+
+const (
+	// stargatesBucket solar system ID to the comma separated IDs of the systems its stargates lead to
+	stargatesBucket string = "stargates"
+	// StargatesFile the SDE mapSolarSystemJumps table, only the fromSolarSystemID and toSolarSystemID columns are used
+	StargatesFile string = "eveSolarSystems/eveStargates.csv"
+	// NoGateRoute the gate jumps of a system that can't be reached by gates, or when there is no stargate data
+	NoGateRoute = -1
+	// stargateWorkers how many systems are fetched from ESI at the same time
+	stargateWorkers = 20
+	// stargateAttempts how many times a system is fetched before it is given up on
+	stargateAttempts = 3
+	// wormholeSystemIDs systems from here on are wormholes and abyssal pockets, they have no stargates
+	wormholeSystemIDs = 31000000
+)
+
+// stargateGraph in memory copy of the stargates bucket, nil until loaded.
+var stargateGraph map[string][]string
+var stargateGraphLock sync.Mutex
+
+// stargateRetryDelay the wait before fetching a system again, doubled each attempt
+var stargateRetryDelay = time.Second
+
+var errNoStargates = errors.New("no stargate data, add the SDE mapSolarSystemJumps table as " + StargatesFile + " or run Eve-Sonar gates fetch")
+
+// HasStargates true when stargate connections have been loaded, without them every gate distance is unknown.
+func HasStargates() bool {
+	return len(loadStargateGraph()) > 0
+}
+
+// FindGateRoute the shortest route through stargates, starting with from and ending with to.
+func FindGateRoute(from SolarSystem, to SolarSystem) ([]SolarSystem, error) {
+	graph := loadStargateGraph()
+	if len(graph) == 0 {
+		return nil, errNoStargates
+	}
+	if from.ID == to.ID {
+		return []SolarSystem{from}, nil
+	}
+
+	previous := map[string]string{from.ID: ""}
+	queue := []string{from.ID}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range graph[current] {
+			if _, visited := previous[next]; visited {
+				continue
+			}
+			previous[next] = current
+			if next != to.ID {
+				queue = append(queue, next)
+				continue
+			}
+
+			var route []SolarSystem
+			for id := to.ID; id != ""; id = previous[id] {
+				solarSystem, _ := FindSystemByID(id)
+				route = append([]SolarSystem{solarSystem}, route...)
+			}
+			return route, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no gate route from %s to %s", from.Name, to.Name)
+}
+
+// GateJumps how many gate jumps apart two systems are, false when there is no gate route.
+func GateJumps(from SolarSystem, to SolarSystem) (int, bool) {
+	route, err := FindGateRoute(from, to)
+	if err != nil {
+		return NoGateRoute, false
+	}
+	return len(route) - 1, true
+}
+
+// SystemsWithinGates every system at most maxJumps gate jumps from a system, nearest first then by name.
+func SystemsWithinGates(from SolarSystem, maxJumps int) ([]GateDistance, error) {
+	if !HasStargates() {
+		return nil, errNoStargates
+	}
+	if maxJumps < 0 {
+		return nil, fmt.Errorf("gate jumps can't be negative")
+	}
+
+	var systems []GateDistance
+	for id, jumps := range gateDistances(from.ID, maxJumps) {
+		if solarSystem, found := FindSystemByID(id); found && id != from.ID {
+			systems = append(systems, GateDistance{System: solarSystem, Jumps: jumps})
+		}
+	}
+	sort.Slice(systems, func(i, j int) bool {
+		if systems[i].Jumps != systems[j].Jumps {
+			return systems[i].Jumps < systems[j].Jumps
+		}
+		return systems[i].System.Name < systems[j].System.Name
+	})
+	return systems, nil
+}
+
+// FormatGateJumps gate jumps for display, blank when there is no gate route.
+func FormatGateJumps(jumps int) string {
+	if jumps == NoGateRoute {
+		return ""
+	}
+	return strconv.Itoa(jumps)
+}
+
+// FormatDistance light years followed by gate jumps when there is a gate route, e.g. "5.01 LY, 7 gates".
+func FormatDistance(lightYears float64, gateJumps int) string {
+	text := fmt.Sprintf("%.2f LY", lightYears)
+	switch gateJumps {
+	case NoGateRoute:
+	case 1:
+		text += ", 1 gate"
+	default:
+		text += fmt.Sprintf(", %d gates", gateJumps)
+	}
+	return text
+}
+
+// BuildStargateIndex fetches every stargate of every known space system from ESI and saves where each one leads,
+// refreshing the bundled connections. Each system is tried a few times, the systems fetched are saved even when
+// others fail and the ones that failed keep what was saved for them before.
+func BuildStargateIndex() error {
+	var systemIDs []int
+	for _, solarSystem := range GetAllSolarSystems() {
+		if id, err := strconv.Atoi(solarSystem.ID); err == nil && id < wormholeSystemIDs {
+			systemIDs = append(systemIDs, id)
+		}
+	}
+
+	graph := make(map[string][]string)
+	var graphLock sync.Mutex
+	var firstErr error
+	var failed int
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < stargateWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for systemID := range jobs {
+				destinations, err := fetchStargateDestinationsRetrying(systemID)
+				graphLock.Lock()
+				if err != nil {
+					failed++
+					if firstErr == nil {
+						firstErr = err
+					}
+				}
+				if len(destinations) > 0 {
+					graph[strconv.Itoa(systemID)] = destinations
+				}
+				graphLock.Unlock()
+			}
+		}()
+	}
+	for _, systemID := range systemIDs {
+		jobs <- systemID
+	}
+	close(jobs)
+	wg.Wait()
+	if len(graph) == 0 {
+		if firstErr != nil {
+			return fmt.Errorf("fetching stargates from ESI: %w", firstErr)
+		}
+		return fmt.Errorf("ESI returned no stargates")
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(stargatesBucket))
+		if err != nil {
+			return err
+		}
+		return putStargates(bucket, graph)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Read back so systems that failed keep their earlier connections
+	stargateGraphLock.Lock()
+	stargateGraph = nil
+	stargateGraphLock.Unlock()
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d systems couldn't be fetched, the rest were saved, run it again to retry them: %w", failed, len(systemIDs), firstErr)
+	}
+	return nil
+}
+
+// WriteStargatesCSV writes the stargate connections in the SDE mapSolarSystemJumps layout, trimmed to the
+// fromSolarSystemID and toSolarSystemID columns, so it can be saved as StargatesFile.
+func WriteStargatesCSV(w io.Writer) error {
+	graph := loadStargateGraph()
+	if len(graph) == 0 {
+		return errNoStargates
+	}
+	systemIDs := make([]string, 0, len(graph))
+	for systemID := range graph {
+		systemIDs = append(systemIDs, systemID)
+	}
+	sort.Strings(systemIDs)
+
+	csvWriter := csv.NewWriter(w)
+	if err := csvWriter.Write([]string{"fromSolarSystemID", "toSolarSystemID"}); err != nil {
+		return err
+	}
+	for _, systemID := range systemIDs {
+		for _, destination := range graph[systemID] {
+			if err := csvWriter.Write([]string{systemID, destination}); err != nil {
+				return err
+			}
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// fetchStargateDestinationsRetrying fetchStargateDestinations, trying again with a growing delay when it fails.
+func fetchStargateDestinationsRetrying(systemID int) ([]string, error) {
+	delay := stargateRetryDelay
+	for attempt := 1; ; attempt++ {
+		destinations, err := fetchStargateDestinations(systemID)
+		if err == nil || attempt == stargateAttempts {
+			return destinations, err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// fetchStargateDestinations the systems a system's stargates lead to, sorted.
+func fetchStargateDestinations(systemID int) ([]string, error) {
+	solarSystem, err := api.GetSolarSystem(systemID)
+	if err != nil {
+		return nil, err
+	}
+	var destinations []string
+	for _, stargateID := range solarSystem.Stargates {
+		stargate, err := api.GetStargate(stargateID)
+		if err != nil {
+			return nil, err
+		}
+		destination := strconv.Itoa(stargate.Destination.SystemID)
+		if !containsString(destinations, destination) {
+			destinations = append(destinations, destination)
+		}
+	}
+	sort.Strings(destinations)
+	return destinations, nil
+}
+
+// gateDistances the gate jumps from a system to every system within maxJumps, or to every reachable system
+// when maxJumps is negative.
+func gateDistances(fromID string, maxJumps int) map[string]int {
+	graph := loadStargateGraph()
+	distances := map[string]int{fromID: 0}
+	queue := []string{fromID}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if maxJumps >= 0 && distances[current] >= maxJumps {
+			continue
+		}
+		for _, next := range graph[current] {
+			if _, visited := distances[next]; !visited {
+				distances[next] = distances[current] + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+	return distances
+}
+
+// gateDistancesFrom gateDistances to every reachable system from the system at some coordinates.
+func gateDistancesFrom(coordinates Coordinates) map[string]int {
+	if !HasStargates() {
+		return nil
+	}
+	for _, solarSystem := range GetAllSolarSystems() {
+		if solarSystem.Coordinates == coordinates {
+			return gateDistances(solarSystem.ID, -1)
+		}
+	}
+	return nil
+}
+
+// loadStargateGraph reads the stargates bucket into memory the first time it has connections.
+func loadStargateGraph() map[string][]string {
+	stargateGraphLock.Lock()
+	defer stargateGraphLock.Unlock()
+	if stargateGraph != nil {
+		return stargateGraph
+	}
+
+	db, err := openDB()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	graph := make(map[string][]string)
+	err = db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(stargatesBucket))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(systemID, destinations []byte) error {
+			graph[string(systemID)] = strings.Split(string(destinations), ",")
+			return nil
+		})
+	})
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// An empty graph isn't kept so connections saved later are picked up
+	if len(graph) > 0 {
+		stargateGraph = graph
+	}
+	return graph
+}
+
+// buildStargateBucket saves the bundled stargate connections both ways, a missing file leaves the bucket empty.
+func buildStargateBucket(bucket *bolt.Bucket) error {
+	stargatesCSV, err := os.Open(StargatesFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer stargatesCSV.Close()
+
+	graph, err := readStargates(stargatesCSV)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", StargatesFile, err)
+	}
+	return putStargates(bucket, graph)
+}
+
+// putStargates saves each system's destinations as a sorted comma separated list.
+func putStargates(bucket *bolt.Bucket, graph map[string][]string) error {
+	for systemID, destinations := range graph {
+		sort.Strings(destinations)
+		if err := bucket.Put([]byte(systemID), []byte(strings.Join(destinations, ","))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readStargates reads the fromSolarSystemID and toSolarSystemID columns, wherever they are in the header.
+func readStargates(reader io.Reader) (map[string][]string, error) {
+	csvReader := csv.NewReader(reader)
+	header, err := csvReader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	fromColumn, toColumn := -1, -1
+	for i, column := range header {
+		switch strings.TrimSpace(column) {
+		case "fromSolarSystemID":
+			fromColumn = i
+		case "toSolarSystemID":
+			toColumn = i
+		}
+	}
+	if fromColumn < 0 || toColumn < 0 {
+		return nil, fmt.Errorf("no fromSolarSystemID and toSolarSystemID columns")
+	}
+
+	graph := make(map[string][]string)
+	seen := make(map[string]struct{})
+	addGate := func(from, to string) {
+		if _, exists := seen[from+">"+to]; !exists {
+			seen[from+">"+to] = struct{}{}
+			graph[from] = append(graph[from], to)
+		}
+	}
+	for {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			return graph, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		from, to := strings.TrimSpace(record[fromColumn]), strings.TrimSpace(record[toColumn])
+		addGate(from, to)
+		addGate(to, from)
+	}
+}
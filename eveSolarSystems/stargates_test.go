@@ -0,0 +1,90 @@
+package eveSolarSystems
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/sythe7448/Eve-Sonar/api"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeStargateESI serves the test stargates plus a new Jita to Old Man Star gate. Nova fails once before it
+// answers and Foxtrot always fails.
+func fakeStargateESI(t *testing.T) *httptest.Server {
+	t.Helper()
+	gates := append([][2]string{{"30000007", "30000008"}}, testStargates...)
+	systemGates := make(map[string][]int)
+	destinations := make(map[int]string)
+	for i, gate := range gates {
+		for direction, from := range gate {
+			stargateID := 50000000 + i*2 + direction
+			systemGates[from] = append(systemGates[from], stargateID)
+			destinations[stargateID] = gate[1-direction]
+		}
+	}
+
+	var novaRequests int
+	var lock sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if systemID, found := strings.CutPrefix(r.URL.Path, "/universe/systems/"); found {
+			systemID = strings.TrimSuffix(systemID, "/")
+			lock.Lock()
+			if systemID == "30000002" {
+				novaRequests++
+			}
+			failNova := systemID == "30000002" && novaRequests == 1
+			lock.Unlock()
+			if failNova || systemID == "30000006" {
+				http.Error(w, "try again", http.StatusBadGateway)
+				return
+			}
+			id, _ := strconv.Atoi(systemID)
+			json.NewEncoder(w).Encode(api.SolarSystemInfo{SystemID: id, Stargates: systemGates[systemID]})
+			return
+		}
+		stargateID, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/universe/stargates/"), "/"))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		var stargate api.StargateInfo
+		stargate.StargateID = stargateID
+		stargate.Destination.SystemID, _ = strconv.Atoi(destinations[stargateID])
+		json.NewEncoder(w).Encode(stargate)
+	}))
+}
+
+func TestBuildStargateIndexKeepsPartialProgress(t *testing.T) {
+	server := fakeStargateESI(t)
+	defer server.Close()
+	baseURL, retryDelay := api.APIBaseURL, stargateRetryDelay
+	api.APIBaseURL, stargateRetryDelay = server.URL, time.Millisecond
+	defer func() { api.APIBaseURL, stargateRetryDelay = baseURL, retryDelay }()
+
+	err := BuildStargateIndex()
+	if err == nil || !strings.Contains(err.Error(), fmt.Sprintf("1 of %d systems", len(testSystems))) {
+		t.Fatalf("got %v, want Foxtrot to fail", err)
+	}
+
+	tests := []struct {
+		from, to string
+		jumps    int
+	}{
+		// Fetched from ESI after a retry for Nova
+		{"Jita", "Old Man Star", 1},
+		{"Alpha", "November", 2},
+		// Foxtrot failed so it keeps the bundled gate
+		{"Foxtrot", "Zulu", 1},
+	}
+	for _, test := range tests {
+		jumps, found := GateJumps(findTestSystem(t, test.from), findTestSystem(t, test.to))
+		if !found || jumps != test.jumps {
+			t.Errorf("%s to %s is %d gates, want %d", test.from, test.to, jumps, test.jumps)
+		}
+	}
+}
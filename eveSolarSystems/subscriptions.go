@@ -0,0 +1,393 @@
+package eveSolarSystems
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	bolt "go.etcd.io/bbolt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Subscription a staging list kept in sync with a file or an http(s) URL, the list is read only while subscribed.
+type Subscription struct {
+	List   string `json:"list"`
+	Source string `json:"source"`
+	// Format one of the StagingFormats, blank picks it from the source's extension
+	Format         string `json:"format,omitempty"`
+	RefreshMinutes int    `json:"refresh_minutes"`
+	ETag           string `json:"etag,omitempty"`
+	// Checksum of the last content synced, so sources without an ETag aren't saved again when unchanged
+	Checksum  string               `json:"checksum,omitempty"`
+	LastSync  time.Time            `json:"last_sync"`
+	LastError string               `json:"last_error,omitempty"`
+	Changes   []SubscriptionChange `json:"changes,omitempty"`
+}
+
+// SubscriptionChange what a sync changed in a subscribed list.
+type SubscriptionChange struct {
+	Time     time.Time      `json:"time"`
+	Added    []string       `json:"added,omitempty"`
+	Updated  []string       `json:"updated,omitempty"`
+	Removed  []string       `json:"removed,omitempty"`
+	Rejected []RejectedLine `json:"rejected,omitempty"`
+	// NotModified the source hasn't changed since the last sync, never saved in the change log
+	NotModified bool `json:"not_modified,omitempty"`
+}
+
+// ErrReadOnlyStagingList returned when editing a subscribed list.
+var ErrReadOnlyStagingList = errors.New("staging list is read only")
+
+const (
+	subscriptionsBucket string = "subscriptions"
+	// DefaultRefreshMinutes how often a subscribed list is synced when no refresh is given
+	DefaultRefreshMinutes = 60
+	// maxSubscriptionChanges how many syncs that changed something are kept per list
+	maxSubscriptionChanges = 20
+	// maxSubscriptionSize the largest source that will be read
+	maxSubscriptionSize = 5 << 20
+)
+
+var subscriptionClient = &http.Client{Timeout: time.Second * 30}
+
+// subscriptionSyncLock only one sync runs at a time so the periodic refresh and Sync now don't race.
+var subscriptionSyncLock sync.Mutex
+var startSubscriptionsOnce sync.Once
+
+// GetSubscriptions returns every subscribed list sorted by list name.
+func GetSubscriptions() []Subscription {
+	db, err := openDB()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	var subscriptions []Subscription
+	err = db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(subscriptionsBucket))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(list, value []byte) error {
+			var subscription Subscription
+			if err := json.Unmarshal(value, &subscription); err != nil {
+				return err
+			}
+			subscriptions = append(subscriptions, subscription)
+			return nil
+		})
+	})
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].List < subscriptions[j].List
+	})
+	return subscriptions
+}
+
+// GetSubscription the subscription of a list, empty when the list isn't subscribed.
+func GetSubscription(list string) Subscription {
+	for _, subscription := range GetSubscriptions() {
+		if strings.EqualFold(subscription.List, strings.TrimSpace(list)) {
+			return subscription
+		}
+	}
+	return Subscription{}
+}
+
+// IsStagingListReadOnly true when a list is subscribed and can only change by syncing.
+func IsStagingListReadOnly(list string) bool {
+	return GetSubscription(list).List != ""
+}
+
+// Subscribe makes a list follow a file or URL, replacing its stagings with the source's. The source is synced
+// once straight away and nothing is saved if that fails. The list is created if it doesn't exist.
+func Subscribe(list string, source string, format string, refreshMinutes int) (Subscription, SubscriptionChange, error) {
+	list = strings.TrimSpace(list)
+	if existing, found := FindStagingList(list); found {
+		list = existing
+	}
+	if list == DefaultStagingList {
+		return Subscription{}, SubscriptionChange{}, fmt.Errorf("the %s list can't be subscribed, use a new list", DefaultStagingList)
+	}
+	if err := validateStagingListName(list); err != nil {
+		return Subscription{}, SubscriptionChange{}, err
+	}
+
+	subscription := Subscription{
+		List:           list,
+		Source:         strings.TrimSpace(source),
+		Format:         strings.ToLower(strings.TrimSpace(format)),
+		RefreshMinutes: refreshMinutes,
+	}
+	if subscription.RefreshMinutes == 0 {
+		subscription.RefreshMinutes = DefaultRefreshMinutes
+	}
+	if subscription.RefreshMinutes < 1 {
+		return Subscription{}, SubscriptionChange{}, fmt.Errorf("refresh must be at least 1 minute")
+	}
+	if subscription.Format != "" && !containsString(StagingFormats, subscription.Format) {
+		return Subscription{}, SubscriptionChange{}, fmt.Errorf("unknown staging format %q, use %s", subscription.Format, strings.Join(StagingFormats, ", "))
+	}
+	if _, err := parseSubscriptionSource(subscription.Source); err != nil {
+		return Subscription{}, SubscriptionChange{}, err
+	}
+
+	subscriptionSyncLock.Lock()
+	defer subscriptionSyncLock.Unlock()
+
+	change, err := syncSubscription(&subscription)
+	if err != nil {
+		return Subscription{}, SubscriptionChange{}, err
+	}
+	if err := saveSubscription(subscription); err != nil {
+		return Subscription{}, SubscriptionChange{}, err
+	}
+	publishSubscriptionChange(subscription.List, change)
+
+	return subscription, change, nil
+}
+
+// Unsubscribe stops syncing a list, its stagings are kept and it can be edited again.
+func Unsubscribe(list string) error {
+	subscription := GetSubscription(list)
+	if subscription.List == "" {
+		return fmt.Errorf("staging list %q isn't subscribed", list)
+	}
+
+	db, err := openDB()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	return db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(subscriptionsBucket)).Delete([]byte(subscription.List))
+	})
+}
+
+// SyncSubscription fetches a subscribed list's source now and saves any changes.
+func SyncSubscription(list string) (SubscriptionChange, error) {
+	subscriptionSyncLock.Lock()
+	defer subscriptionSyncLock.Unlock()
+
+	subscription := GetSubscription(list)
+	if subscription.List == "" {
+		return SubscriptionChange{}, fmt.Errorf("staging list %q isn't subscribed", list)
+	}
+
+	change, err := syncSubscription(&subscription)
+	subscription.LastError = ""
+	if err != nil {
+		subscription.LastError = err.Error()
+	}
+	if saveErr := saveSubscription(subscription); saveErr != nil {
+		return SubscriptionChange{}, saveErr
+	}
+	publishSubscriptionChange(subscription.List, change)
+
+	return change, err
+}
+
+// StartSubscriptions syncs every subscribed list once its refresh time has passed, only the first call does anything.
+func StartSubscriptions() {
+	startSubscriptionsOnce.Do(func() {
+		go func() {
+			for {
+				for _, subscription := range GetSubscriptions() {
+					if time.Since(subscription.LastSync) < time.Duration(subscription.RefreshMinutes)*time.Minute {
+						continue
+					}
+					if _, err := SyncSubscription(subscription.List); err != nil {
+						log.Printf("Error syncing staging list %s: %s", subscription.List, err)
+					}
+				}
+				time.Sleep(time.Minute)
+			}
+		}()
+	})
+}
+
+// Summary one line describing the change, for the CLI and the change log.
+func (c SubscriptionChange) Summary() string {
+	if c.NotModified {
+		return "not modified"
+	}
+	var parts []string
+	if len(c.Added) > 0 {
+		parts = append(parts, "added "+strings.Join(c.Added, ", "))
+	}
+	if len(c.Updated) > 0 {
+		parts = append(parts, "updated "+strings.Join(c.Updated, ", "))
+	}
+	if len(c.Removed) > 0 {
+		parts = append(parts, "removed "+strings.Join(c.Removed, ", "))
+	}
+	if len(c.Rejected) > 0 {
+		parts = append(parts, fmt.Sprintf("%d rejected", len(c.Rejected)))
+	}
+	if len(parts) == 0 {
+		return "no changes"
+	}
+	return strings.Join(parts, "; ")
+}
+
+func (c SubscriptionChange) changed() bool {
+	return len(c.Added)+len(c.Updated)+len(c.Removed)+len(c.Rejected) > 0
+}
+
+// publishSubscriptionChange tells the UI and event stream a sync changed a list.
+func publishSubscriptionChange(list string, change SubscriptionChange) {
+	if change.changed() {
+		Events.Publish(Event{Type: EventStagingListSynced, Time: change.Time, List: list, Sync: &change})
+	}
+}
+
+// syncSubscription fetches the source and replaces the list with it, the caller saves the subscription.
+// The sync lock must be held.
+func syncSubscription(subscription *Subscription) (SubscriptionChange, error) {
+	subscription.LastSync = time.Now()
+	data, etag, err := fetchSubscriptionSource(subscription.Source, subscription.ETag)
+	if err != nil {
+		return SubscriptionChange{}, err
+	}
+	if data == nil {
+		return SubscriptionChange{Time: subscription.LastSync, NotModified: true}, nil
+	}
+
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+	subscription.ETag = etag
+	if checksum == subscription.Checksum {
+		return SubscriptionChange{Time: subscription.LastSync, NotModified: true}, nil
+	}
+
+	format := subscription.Format
+	if format == "" {
+		source, _ := parseSubscriptionSource(subscription.Source)
+		format = StagingFormatFromFilename(source.Path)
+	}
+	entries, rejected, err := ParseStagingFile(data, format)
+	if err != nil {
+		return SubscriptionChange{}, err
+	}
+	existing := GetStagingList(subscription.List)
+	// An empty source is more likely a broken upload than a list with no hostiles left
+	if len(entries) == 0 && len(existing) > 0 {
+		subscription.ETag = ""
+		return SubscriptionChange{}, fmt.Errorf("%s has no stagings, keeping the current list", subscription.Source)
+	}
+
+	stagings, result := MergeStagings(existing, entries, true)
+	if err := saveStagingList(subscription.List, stagings); err != nil {
+		return SubscriptionChange{}, err
+	}
+	subscription.Checksum = checksum
+
+	change := SubscriptionChange{
+		Time:     subscription.LastSync,
+		Added:    result.Added,
+		Updated:  result.Updated,
+		Removed:  result.Removed,
+		Rejected: rejected,
+	}
+	// Newest first, syncs that only changed the file's formatting aren't logged
+	if change.changed() {
+		subscription.Changes = append([]SubscriptionChange{change}, subscription.Changes...)
+		if len(subscription.Changes) > maxSubscriptionChanges {
+			subscription.Changes = subscription.Changes[:maxSubscriptionChanges]
+		}
+		recheckRangesInBackground()
+	}
+
+	return change, nil
+}
+
+// fetchSubscriptionSource reads a file or does a conditional GET. data is nil when the server says it's not modified.
+func fetchSubscriptionSource(source string, etag string) ([]byte, string, error) {
+	sourceURL, err := parseSubscriptionSource(source)
+	if err != nil {
+		return nil, "", err
+	}
+	if sourceURL.Scheme == "file" {
+		file, err := os.Open(sourceURL.Path)
+		if err != nil {
+			return nil, "", err
+		}
+		defer file.Close()
+		data, err := io.ReadAll(io.LimitReader(file, maxSubscriptionSize))
+		return data, "", err
+	}
+
+	req, err := http.NewRequest("GET", sourceURL.String(), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	resp, err := subscriptionClient.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, etag, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("%s returned %s", sourceURL.Redacted(), resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSubscriptionSize))
+	return data, resp.Header.Get("ETag"), err
+}
+
+// parseSubscriptionSource an http(s) URL as is, anything else is a file path returned as a file URL.
+func parseSubscriptionSource(source string) (*url.URL, error) {
+	if source == "" {
+		return nil, fmt.Errorf("subscription source is blank")
+	}
+	lower := strings.ToLower(source)
+	if strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") {
+		sourceURL, err := url.Parse(source)
+		if err != nil || sourceURL.Host == "" {
+			return nil, fmt.Errorf("subscription source %q isn't a valid URL", source)
+		}
+		return sourceURL, nil
+	}
+	return &url.URL{Scheme: "file", Path: strings.TrimPrefix(source, "file://")}, nil
+}
+
+// saveSubscription saves a subscription, replacing the list's previous one.
+func saveSubscription(subscription Subscription) error {
+	value, err := json.Marshal(subscription)
+	if err != nil {
+		return err
+	}
+
+	db, err := openDB()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	return db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(subscriptionsBucket))
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(subscription.List), value)
+	})
+}
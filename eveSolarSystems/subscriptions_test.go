@@ -0,0 +1,136 @@
+package eveSolarSystems
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// subscriptionResponse what the test source answers one sync with.
+type subscriptionResponse struct {
+	etag string
+	body string
+}
+
+func TestSubscriptionSync(t *testing.T) {
+	responses := []subscriptionResponse{
+		{`"v1"`, "system,note\nAlpha,keepstar\nNova,\n"},
+		// Answered with 304 while the ETag matches
+		{`"v1"`, ""},
+		{`"v2"`, "system,note\nAlpha,fortizar\nNiner,\n"},
+		{`"v3"`, ""},
+		// No ETag and the same content as v2, only the checksum can tell
+		{"", "system,note\nAlpha,fortizar\nNiner,\n"},
+	}
+	var requests []string
+	var lock sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		response := responses[len(requests)]
+		requests = append(requests, r.Header.Get("If-None-Match"))
+		if response.etag != "" && r.Header.Get("If-None-Match") == response.etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		if response.etag != "" {
+			w.Header().Set("ETag", response.etag)
+		}
+		w.Write([]byte(response.body))
+	}))
+	defer server.Close()
+
+	const list = "Subscribed Hostiles"
+	defer DeleteStagingList(list)
+
+	_, change, err := Subscribe(list, server.URL+"/hostiles.csv", "", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(change.Added, []string{"Alpha", "Nova"}) {
+		t.Errorf("subscribing added %v, want Alpha and Nova", change.Added)
+	}
+
+	change, err = SyncSubscription(list)
+	if err != nil || !change.NotModified {
+		t.Errorf("got %s, %v, want not modified", change.Summary(), err)
+	}
+
+	change, err = SyncSubscription(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := change.Summary(); got != "added Niner; updated Alpha; removed Nova" {
+		t.Errorf("got %s, want Niner added, Alpha updated and Nova removed", got)
+	}
+
+	// An empty source is refused and the list kept as it was
+	if _, err = SyncSubscription(list); err == nil || !strings.Contains(err.Error(), "has no stagings") {
+		t.Errorf("got %v, want the empty source refused", err)
+	}
+	want := map[string]string{"Alpha": "fortizar", "Niner": ""}
+	if got := GetStagingList(list); !reflect.DeepEqual(got, want) {
+		t.Errorf("list is %v after the empty source, want %v", got, want)
+	}
+	if subscription := GetSubscription(list); !strings.Contains(subscription.LastError, "has no stagings") {
+		t.Errorf("last error %q, want the empty source", subscription.LastError)
+	}
+
+	change, err = SyncSubscription(list)
+	if err != nil || !change.NotModified {
+		t.Errorf("got %s, %v, want the unchanged checksum seen as not modified", change.Summary(), err)
+	}
+
+	// The refused ETag is forgotten so the next sync fetches everything again
+	if wantRequests := []string{"", `"v1"`, `"v1"`, `"v2"`, ""}; !reflect.DeepEqual(requests, wantRequests) {
+		t.Errorf("sent If-None-Match %q, want %q", requests, wantRequests)
+	}
+
+	// Only syncs that changed something are logged, newest first
+	var summaries []string
+	for _, logged := range GetSubscription(list).Changes {
+		summaries = append(summaries, logged.Summary())
+	}
+	if wantSummaries := []string{"added Niner; updated Alpha; removed Nova", "added Alpha, Nova"}; !reflect.DeepEqual(summaries, wantSummaries) {
+		t.Errorf("change log %q, want %q", summaries, wantSummaries)
+	}
+}
+
+func TestSubscribedListReadOnly(t *testing.T) {
+	const list = "Subscribed Friendlies"
+	source := filepath.Join(t.TempDir(), "friendlies.txt")
+	if err := os.WriteFile(source, []byte("Foxtrot\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer DeleteStagingList(list)
+
+	if _, _, err := Subscribe(list, source, "", 0); err != nil {
+		t.Fatal(err)
+	}
+	if !IsStagingListReadOnly(list) {
+		t.Fatal("subscribed list isn't read only")
+	}
+	if _, err := AddStagingSystem(list, "Zulu", ""); !errors.Is(err, ErrReadOnlyStagingList) {
+		t.Errorf("adding got %v, want read only", err)
+	}
+	if err := RemoveStagingSystem(list, "Foxtrot"); !errors.Is(err, ErrReadOnlyStagingList) {
+		t.Errorf("removing got %v, want read only", err)
+	}
+
+	// Unsubscribing keeps the stagings and the list can be edited again
+	if err := Unsubscribe(list); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AddStagingSystem(list, "Zulu", ""); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"Foxtrot": "", "Zulu": ""}; !reflect.DeepEqual(GetStagingList(list), want) {
+		t.Errorf("list is %v, want %v", GetStagingList(list), want)
+	}
+}
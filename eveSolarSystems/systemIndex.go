@@ -0,0 +1,180 @@
+package eveSolarSystems
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	bolt "go.etcd.io/bbolt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// maxSystemNameWords the longest system names are three words, e.g. Old Man Star
+const maxSystemNameWords = 3
+
+// placeholderSystemName unnamed systems in the dataset that aren't reachable k-space
+const placeholderSystemName = "No System Name"
+
+// newEdenIDPrefix k-space system IDs, the rest of the dataset sits far outside the cluster
+const newEdenIDPrefix = "300"
+
+// minFuzzyPrefix intel abbreviations shorter than this are too ambiguous to match
+const minFuzzyPrefix = 3
+
+// systemIndex an in memory copy of the solar systems bucket for lookups that would otherwise scan bolt every time.
+type systemIndex struct {
+	systems []SolarSystem
+	byName  map[string]SolarSystem
+	byID    map[string]SolarSystem
+	names   []string
+}
+
+var loadedSystemIndex *systemIndex
+var systemIndexOnce sync.Once
+
+// getSystemIndex loads the index the first time it is needed.
+func getSystemIndex() *systemIndex {
+	systemIndexOnce.Do(func() {
+		db, err := openDB()
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer db.Close()
+
+		index := &systemIndex{
+			byName: make(map[string]SolarSystem),
+			byID:   make(map[string]SolarSystem),
+		}
+		err = db.View(func(tx *bolt.Tx) error {
+			bucket := tx.Bucket([]byte(solarSystemsBucket))
+			if bucket == nil {
+				return fmt.Errorf("bucket not found")
+			}
+			return bucket.ForEach(func(key, value []byte) error {
+				var solarSystem SolarSystem
+				decoder := gob.NewDecoder(bytes.NewReader(value))
+				if err := decoder.Decode(&solarSystem); err != nil {
+					return err
+				}
+				if solarSystem.Name == placeholderSystemName {
+					return nil
+				}
+				index.systems = append(index.systems, solarSystem)
+				index.byID[solarSystem.ID] = solarSystem
+				return nil
+			})
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		sort.Slice(index.systems, func(i, j int) bool {
+			return index.systems[i].Name < index.systems[j].Name
+		})
+		for _, solarSystem := range index.systems {
+			name := strings.ToLower(solarSystem.Name)
+			index.byName[name] = solarSystem
+			index.names = append(index.names, name)
+		}
+		sort.Strings(index.names)
+		loadedSystemIndex = index
+	})
+
+	return loadedSystemIndex
+}
+
+// GetAllSolarSystems every known solar system sorted by name.
+func GetAllSolarSystems() []SolarSystem {
+	return getSystemIndex().systems
+}
+
+// FindSystemByName case insensitive exact name lookup without touching bolt.
+func FindSystemByName(name string) (SolarSystem, bool) {
+	solarSystem, found := getSystemIndex().byName[strings.ToLower(strings.TrimSpace(name))]
+	return solarSystem, found
+}
+
+// FindSystemByID id lookup without touching bolt.
+func FindSystemByID(id string) (SolarSystem, bool) {
+	solarSystem, found := getSystemIndex().byID[id]
+	return solarSystem, found
+}
+
+// SearchSystems returns systems whose name starts with prefix, case insensitive.
+func SearchSystems(prefix string) []SolarSystem {
+	index := getSystemIndex()
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	if prefix == "" {
+		return nil
+	}
+
+	var matches []SolarSystem
+	for i := sort.SearchStrings(index.names, prefix); i < len(index.names); i++ {
+		if !strings.HasPrefix(index.names[i], prefix) {
+			break
+		}
+		matches = append(matches, index.byName[index.names[i]])
+	}
+	return matches
+}
+
+// MatchSystemsInText pulls every system name out of free text such as an intel report.
+// Multi word names are tried first. Nullsec style names (containing digits or dashes) also match
+// on a unique abbreviation like "1DQ", while plain word names must be capitalised so words like "exit" are skipped.
+func MatchSystemsInText(text string) []SolarSystem {
+	index := getSystemIndex()
+	words := strings.Fields(text)
+	for i, word := range words {
+		words[i] = strings.Trim(word, ".,;:!?()[]{}<>\"'*")
+	}
+
+	var matches []SolarSystem
+	seen := make(map[string]struct{})
+	addMatch := func(solarSystem SolarSystem) {
+		if _, exists := seen[solarSystem.ID]; !exists {
+			seen[solarSystem.ID] = struct{}{}
+			matches = append(matches, solarSystem)
+		}
+	}
+
+	for i := 0; i < len(words); i++ {
+		matched := false
+		for n := maxSystemNameWords; n > 1 && !matched; n-- {
+			if i+n > len(words) {
+				continue
+			}
+			if solarSystem, found := index.byName[strings.ToLower(strings.Join(words[i:i+n], " "))]; found {
+				addMatch(solarSystem)
+				i += n - 1
+				matched = true
+			}
+		}
+		if matched || words[i] == "" {
+			continue
+		}
+
+		word := words[i]
+		if solarSystem, found := index.byName[strings.ToLower(word)]; found {
+			if isNullsecStyleName(solarSystem.Name) || unicode.IsUpper([]rune(word)[0]) {
+				addMatch(solarSystem)
+			}
+			continue
+		}
+		if len(word) >= minFuzzyPrefix && isNullsecStyleName(word) {
+			candidates := SearchSystems(word)
+			if len(candidates) == 1 {
+				addMatch(candidates[0])
+			}
+		}
+	}
+
+	return matches
+}
+
+// isNullsecStyleName true for generated names such as 1DQ1-A that contain digits or dashes.
+func isNullsecStyleName(name string) bool {
+	return strings.ContainsAny(name, "0123456789-")
+}
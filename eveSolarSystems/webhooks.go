@@ -0,0 +1,402 @@
+package eveSolarSystems
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	bolt "go.etcd.io/bbolt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Webhook an outgoing webhook fired on range events, empty filters match everything.
+type Webhook struct {
+	Name       string   `json:"name"`
+	URL        string   `json:"url"`
+	Format     string   `json:"format"`
+	Events     []string `json:"events,omitempty"`
+	Lists      []string `json:"lists,omitempty"`
+	Profiles   []string `json:"profiles,omitempty"`
+	Characters []string `json:"characters,omitempty"`
+}
+
+// Webhook payload formats
+const (
+	WebhookDiscord = "discord"
+	WebhookSlack   = "slack"
+	WebhookJSON    = "json"
+)
+
+// WebhookFormats every payload format, in the order they are shown.
+var WebhookFormats = []string{WebhookDiscord, WebhookSlack, WebhookJSON}
+
+// WebhookEventTypes the events a webhook can be fired on.
+var WebhookEventTypes = []string{EventStagingEnteredRange, EventStagingLeftRange}
+
+const (
+	webhooksBucket string = "webhooks"
+	// webhookAttempts how many times a delivery is tried before it is dropped
+	webhookAttempts = 5
+	// webhookRetryDelay the wait before the first retry, doubled for each retry after
+	webhookRetryDelay = time.Second * 2
+	// webhookMaxRetryDelay caps the backoff and any Retry-After asked for by the receiver
+	webhookMaxRetryDelay = time.Minute
+)
+
+var webhookClient = &http.Client{Timeout: time.Second * 10}
+
+// webhookSleep waits between delivery attempts, a variable so tests don't have to.
+var webhookSleep = time.Sleep
+
+// webhookQueues one queue per webhook so each receiver gets its events in order.
+var webhookQueues = make(map[string]chan Event)
+var webhookQueuesLock sync.Mutex
+var startWebhooksOnce sync.Once
+
+// GetWebhooks returns the saved webhooks sorted by name.
+func GetWebhooks() []Webhook {
+	db, err := openDB()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	var webhooks []Webhook
+	err = db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(webhooksBucket))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(name, value []byte) error {
+			var webhook Webhook
+			if err := json.Unmarshal(value, &webhook); err != nil {
+				return err
+			}
+			webhooks = append(webhooks, webhook)
+			return nil
+		})
+	})
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].Name < webhooks[j].Name
+	})
+	return webhooks
+}
+
+// FindWebhook a saved webhook by name, case insensitive.
+func FindWebhook(name string) (Webhook, bool) {
+	for _, webhook := range GetWebhooks() {
+		if strings.EqualFold(webhook.Name, strings.TrimSpace(name)) {
+			return webhook, true
+		}
+	}
+	return Webhook{}, false
+}
+
+// SaveWebhook validates a webhook and saves it, replacing any webhook with the same name.
+func SaveWebhook(webhook Webhook) (Webhook, error) {
+	webhook, err := validateWebhook(webhook)
+	if err != nil {
+		return Webhook{}, err
+	}
+	if existing, found := FindWebhook(webhook.Name); found {
+		if err := RemoveWebhook(existing.Name); err != nil {
+			return Webhook{}, err
+		}
+	}
+
+	value, err := json.Marshal(webhook)
+	if err != nil {
+		return Webhook{}, err
+	}
+
+	db, err := openDB()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(webhooksBucket))
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(webhook.Name), value)
+	})
+	if err != nil {
+		return Webhook{}, err
+	}
+
+	return webhook, nil
+}
+
+// RemoveWebhook removes a webhook by name, case insensitive.
+func RemoveWebhook(name string) error {
+	webhook, found := FindWebhook(name)
+	if !found {
+		return fmt.Errorf("unknown webhook %q", name)
+	}
+
+	db, err := openDB()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	return db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(webhooksBucket)).Delete([]byte(webhook.Name))
+	})
+}
+
+// Matches true when the event passes every filter of the webhook.
+func (w Webhook) Matches(event Event) bool {
+	eventTypes := w.Events
+	if len(eventTypes) == 0 {
+		eventTypes = WebhookEventTypes
+	}
+	if !containsString(eventTypes, event.Type) || event.Staging == nil {
+		return false
+	}
+
+	return matchesFilter(w.Lists, event.Staging.List) &&
+		matchesFilter(w.Profiles, event.Profile) &&
+		matchesFilter(w.Characters, event.Character)
+}
+
+// StartWebhooks starts delivering range events to the saved webhooks, only the first call does anything.
+func StartWebhooks() {
+	startWebhooksOnce.Do(func() {
+		go func(events <-chan Event) {
+			for event := range events {
+				for _, webhook := range GetWebhooks() {
+					if webhook.Matches(event) {
+						queueWebhook(webhook, event)
+					}
+				}
+			}
+		}(Events.Subscribe())
+	})
+}
+
+// SendWebhook delivers an event, retrying with backoff while the receiver is down or rate limiting.
+func SendWebhook(webhook Webhook, event Event) error {
+	payload, err := buildWebhookPayload(webhook, event)
+	if err != nil {
+		return err
+	}
+
+	delay := webhookRetryDelay
+	for attempt := 1; ; attempt++ {
+		retryAfter, err := postWebhook(webhook.URL, payload)
+		if err == nil {
+			return nil
+		}
+		if retryAfter < 0 || attempt == webhookAttempts {
+			return fmt.Errorf("webhook %s: %w", webhook.Name, err)
+		}
+		if retryAfter < delay {
+			retryAfter = delay
+		}
+		if retryAfter > webhookMaxRetryDelay {
+			retryAfter = webhookMaxRetryDelay
+		}
+		webhookSleep(retryAfter)
+		delay *= 2
+	}
+}
+
+// TestWebhook sends a made up staging_entered_range event marked as a test, without retrying.
+func TestWebhook(webhook Webhook) error {
+	webhook, err := validateWebhook(webhook)
+	if err != nil {
+		return err
+	}
+
+	// Use a staging and names that pass the webhook's own filters
+	event := Event{
+		Type:      EventStagingEnteredRange,
+		Time:      time.Now(),
+		Character: "Eve Sonar",
+		Profile:   ShipRangeNames[0],
+		Test:      true,
+	}
+	if len(webhook.Characters) > 0 {
+		event.Character = webhook.Characters[0]
+	}
+	if len(webhook.Profiles) > 0 {
+		event.Profile = webhook.Profiles[0]
+	}
+	staging := StagingInRange{System: SolarSystem{Name: "Test system"}, List: DefaultStagingList}
+	if len(webhook.Lists) > 0 {
+		staging.List = webhook.Lists[0]
+	}
+	for _, entry := range GetAllStagings() {
+		if matchesFilter(webhook.Lists, entry.List) {
+			if solarSystem, found := FindSystemByName(entry.System); found {
+				staging = StagingInRange{System: solarSystem, List: entry.List, Owner: entry.Note}
+				break
+			}
+		}
+	}
+	event.Staging = &staging
+
+	payload, err := buildWebhookPayload(webhook, event)
+	if err != nil {
+		return err
+	}
+	_, err = postWebhook(webhook.URL, payload)
+	return err
+}
+
+// RangeChangeMessage the human readable line for a range change, sent to chat webhooks and desktop notifications.
+func RangeChangeMessage(event Event) string {
+	if event.Staging == nil {
+		return event.Type
+	}
+
+	action := "entered"
+	if event.Type == EventStagingLeftRange {
+		action = "left"
+	}
+	staging := event.Staging.System.Name
+	if event.Staging.Owner != "" {
+		staging += " " + event.Staging.Owner
+	}
+	message := fmt.Sprintf("%s [%s] %s %s range of %s", staging, event.Staging.List, action, event.Profile, event.Character)
+	if event.Location != nil {
+		message += fmt.Sprintf(" in %s (%s)", event.Location.Name, FormatDistance(event.Staging.LightYears, event.Staging.GateJumps))
+	}
+	if event.Test {
+		message = "[Test] " + message
+	}
+	return message
+}
+
+func queueWebhook(webhook Webhook, event Event) {
+	webhookQueuesLock.Lock()
+	defer webhookQueuesLock.Unlock()
+
+	queue, exists := webhookQueues[webhook.Name]
+	if !exists {
+		queue = make(chan Event, subscriberBuffer)
+		webhookQueues[webhook.Name] = queue
+		go func(name string) {
+			for event := range queue {
+				// Read the webhook again in case it was edited or removed while events were queued
+				webhook, found := FindWebhook(name)
+				if !found {
+					continue
+				}
+				if err := SendWebhook(webhook, event); err != nil {
+					log.Println("Error sending webhook:", err)
+				}
+			}
+		}(webhook.Name)
+	}
+
+	select {
+	case queue <- event:
+	default:
+		log.Printf("Webhook %s is too far behind, dropping %s event", webhook.Name, event.Type)
+	}
+}
+
+func buildWebhookPayload(webhook Webhook, event Event) ([]byte, error) {
+	switch webhook.Format {
+	case WebhookDiscord:
+		return json.Marshal(map[string]string{"username": "Eve Sonar", "content": RangeChangeMessage(event)})
+	case WebhookSlack:
+		return json.Marshal(map[string]string{"text": RangeChangeMessage(event)})
+	case WebhookJSON:
+		return json.Marshal(event)
+	}
+	return nil, fmt.Errorf("unknown webhook format %q", webhook.Format)
+}
+
+// postWebhook posts the payload once. On failure retryAfter is how long the receiver asked to wait,
+// 0 when it didn't say and -1 when retrying won't help.
+func postWebhook(webhookURL string, payload []byte) (time.Duration, error) {
+	resp, err := webhookClient.Post(webhookURL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return 0, nil
+	}
+	err = fmt.Errorf("receiver returned %s", resp.Status)
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		seconds, _ := strconv.ParseFloat(resp.Header.Get("Retry-After"), 64)
+		return time.Duration(seconds * float64(time.Second)), err
+	}
+	return -1, err
+}
+
+// validateWebhook checks a webhook and matches its filters to the names used in events.
+func validateWebhook(webhook Webhook) (Webhook, error) {
+	webhook.Name = strings.TrimSpace(webhook.Name)
+	if webhook.Name == "" {
+		return Webhook{}, fmt.Errorf("webhook name is blank")
+	}
+	webhookURL, err := url.Parse(strings.TrimSpace(webhook.URL))
+	if err != nil || (webhookURL.Scheme != "http" && webhookURL.Scheme != "https") || webhookURL.Host == "" {
+		return Webhook{}, fmt.Errorf("webhook URL %q must be an http or https URL", webhook.URL)
+	}
+	webhook.URL = webhookURL.String()
+
+	webhook.Format = strings.ToLower(strings.TrimSpace(webhook.Format))
+	if webhook.Format == "" {
+		webhook.Format = WebhookJSON
+	}
+	if !containsString(WebhookFormats, webhook.Format) {
+		return Webhook{}, fmt.Errorf("unknown webhook format %q, use %s", webhook.Format, strings.Join(WebhookFormats, ", "))
+	}
+
+	for _, eventType := range webhook.Events {
+		if !containsString(WebhookEventTypes, eventType) {
+			return Webhook{}, fmt.Errorf("unknown webhook event %q, use %s", eventType, strings.Join(WebhookEventTypes, ", "))
+		}
+	}
+	for i, list := range webhook.Lists {
+		listName, found := FindStagingList(list)
+		if !found {
+			return Webhook{}, fmt.Errorf("unknown staging list %q", list)
+		}
+		webhook.Lists[i] = listName
+	}
+	for i, profile := range webhook.Profiles {
+		profileName, found := FindShipRange(profile)
+		if !found {
+			return Webhook{}, fmt.Errorf("unknown range profile %q, use %s", profile, strings.Join(ShipRangeNames, ", "))
+		}
+		webhook.Profiles[i] = profileName
+	}
+
+	return webhook, nil
+}
+
+// matchesFilter an empty filter matches everything.
+func matchesFilter(filter []string, value string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	for _, allowed := range filter {
+		if strings.EqualFold(allowed, value) {
+			return true
+		}
+	}
+	return false
+}
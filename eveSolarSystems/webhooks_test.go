@@ -0,0 +1,164 @@
+package eveSolarSystems
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testRangeEvent a staging entering or leaving range of a character, the staging in the given list.
+func testRangeEvent(eventType string, list string, system string) Event {
+	return Event{
+		Type:      eventType,
+		Character: "Webhook Tester",
+		Profile:   "Capitals",
+		Staging:   &StagingInRange{System: SolarSystem{Name: system}, List: list},
+	}
+}
+
+// webhookReply what the test receiver answers one POST with, a zero Retry-After isn't sent.
+type webhookReply struct {
+	status     int
+	retryAfter int
+}
+
+func TestSendWebhookRetries(t *testing.T) {
+	var waits []time.Duration
+	sleep := webhookSleep
+	webhookSleep = func(wait time.Duration) { waits = append(waits, wait) }
+	defer func() { webhookSleep = sleep }()
+
+	tests := []struct {
+		name    string
+		replies []webhookReply
+		wantErr bool
+		waits   []time.Duration
+	}{
+		{"delivered after a server error", []webhookReply{{500, 0}, {200, 0}}, false, []time.Duration{2 * time.Second}},
+		// Retry-After is waited when longer than the backoff, and capped at a minute
+		{"rate limited", []webhookReply{{429, 30}, {429, 1}, {429, 600}, {204, 0}}, false,
+			[]time.Duration{30 * time.Second, 4 * time.Second, time.Minute}},
+		{"client error isn't retried", []webhookReply{{400, 0}}, true, nil},
+		{"dropped after five attempts", []webhookReply{{502, 0}, {502, 0}, {502, 0}, {502, 0}, {502, 0}}, true,
+			[]time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			waits = nil
+			var posts int
+			var lock sync.Mutex
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				lock.Lock()
+				defer lock.Unlock()
+				reply := test.replies[posts]
+				posts++
+				if reply.retryAfter > 0 {
+					w.Header().Set("Retry-After", strconv.Itoa(reply.retryAfter))
+				}
+				w.WriteHeader(reply.status)
+			}))
+			defer server.Close()
+
+			err := SendWebhook(Webhook{Name: "Retries", URL: server.URL, Format: WebhookJSON}, testRangeEvent(EventStagingEnteredRange, DefaultStagingList, "Alpha"))
+			if (err != nil) != test.wantErr {
+				t.Errorf("got %v, want an error %t", err, test.wantErr)
+			}
+			lock.Lock()
+			defer lock.Unlock()
+			if posts != len(test.replies) {
+				t.Errorf("posted %d times, want %d", posts, len(test.replies))
+			}
+			if !reflect.DeepEqual(waits, test.waits) {
+				t.Errorf("waited %v, want %v", waits, test.waits)
+			}
+		})
+	}
+}
+
+func TestWebhookMatches(t *testing.T) {
+	webhook := Webhook{Events: []string{EventStagingEnteredRange}, Lists: []string{"Hostiles"}, Profiles: []string{"Capitals"}}
+	entered := testRangeEvent(EventStagingEnteredRange, "hostiles", "Alpha")
+	tests := []struct {
+		name    string
+		webhook Webhook
+		event   Event
+		want    bool
+	}{
+		{"every filter passes, lists ignore case", webhook, entered, true},
+		{"other event type", webhook, testRangeEvent(EventStagingLeftRange, "Hostiles", "Alpha"), false},
+		{"other list", webhook, testRangeEvent(EventStagingEnteredRange, "Friendlies", "Alpha"), false},
+		{"other profile", webhook, Event{Type: EventStagingEnteredRange, Profile: "Supers", Staging: entered.Staging}, false},
+		{"character filter", Webhook{Characters: []string{"Someone Else"}}, entered, false},
+		// No event filter is every range event, but never the others
+		{"no filters", Webhook{}, testRangeEvent(EventStagingLeftRange, "Friendlies", "Alpha"), true},
+		{"not a range event", Webhook{}, Event{Type: EventCharacterMoved, Character: "Webhook Tester"}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.webhook.Matches(test.event); got != test.want {
+				t.Errorf("got %t, want %t", got, test.want)
+			}
+		})
+	}
+}
+
+func TestWebhookQueues(t *testing.T) {
+	const list = "Webhook Targets"
+	if _, err := AddStagingSystem(list, "Alpha", ""); err != nil {
+		t.Fatal(err)
+	}
+	defer DeleteStagingList(list)
+
+	// The stuck receiver holds its one POST until the test ends, with nothing queued behind it to send after
+	stuck := make(chan struct{})
+	stuckServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-stuck
+	}))
+	defer stuckServer.Close()
+	defer close(stuck)
+
+	received := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event Event
+		json.NewDecoder(r.Body).Decode(&event)
+		received <- event.Staging.System.Name
+	}))
+	defer server.Close()
+
+	for _, webhook := range []Webhook{
+		{Name: "Queue Stuck", URL: stuckServer.URL, Lists: []string{DefaultStagingList}},
+		{Name: "Queue Targets", URL: server.URL, Lists: []string{list}},
+	} {
+		if _, err := SaveWebhook(webhook); err != nil {
+			t.Fatal(err)
+		}
+		defer RemoveWebhook(webhook.Name)
+	}
+	StartWebhooks()
+
+	// The stuck receiver doesn't hold up the other, which never gets the event for a list it doesn't match
+	Events.Publish(testRangeEvent(EventStagingEnteredRange, DefaultStagingList, "Zulu"))
+	for _, system := range []string{"Nova", "November", "Niner"} {
+		Events.Publish(testRangeEvent(EventStagingEnteredRange, list, system))
+	}
+	for _, want := range []string{"Nova", "November", "Niner"} {
+		select {
+		case got := <-received:
+			if got != want {
+				t.Errorf("got %s, want %s", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for %s", want)
+		}
+	}
+	select {
+	case got := <-received:
+		t.Errorf("got %s, want nothing more", got)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
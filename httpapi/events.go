@@ -0,0 +1,67 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/sythe7448/Eve-Sonar/eveSolarSystems"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// heartbeatInterval keeps idle connections from being closed by proxies
+const heartbeatInterval = time.Second * 30
+
+// GET /events?type=staging_entered_range,staging_left_range
+// Streams change events as server-sent events until the client disconnects.
+func handleEvents(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, fmt.Errorf("streaming is not supported"))
+		return
+	}
+
+	types := make(map[string]struct{})
+	for _, eventType := range strings.Split(r.URL.Query().Get("type"), ",") {
+		if eventType = strings.TrimSpace(eventType); eventType != "" {
+			types[eventType] = struct{}{}
+		}
+	}
+
+	events := eveSolarSystems.Events.Subscribe()
+	defer eveSolarSystems.Events.Unsubscribe(events)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+		case event, open := <-events:
+			if !open {
+				return
+			}
+			if _, wanted := types[event.Type]; len(types) > 0 && !wanted {
+				continue
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+		}
+		flusher.Flush()
+	}
+}
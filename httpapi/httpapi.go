@@ -0,0 +1,569 @@
+package httpapi
+
+import (
+	"crypto/subtle"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/sythe7448/Eve-Sonar/eveSolarSystems"
+	"mime"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// openAPISpec documents every endpoint, served at /openapi.yaml
+//
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+// SystemInfo a solar system with its current sovereignty holder.
+type SystemInfo struct {
+	eveSolarSystems.SolarSystem
+	Sov string `json:"sov"`
+}
+
+// Staging a saved staging system, in the default list when no list is given.
+type Staging struct {
+	List   string `json:"list"`
+	System string `json:"system"`
+	Note   string `json:"note"`
+}
+
+// Location where a tracked character was last seen.
+type Location struct {
+	eveSolarSystems.LocationEvent
+	SolarSystemName string `json:"solar_system_name"`
+}
+
+// GateRoute the shortest stargate route between two systems.
+type GateRoute struct {
+	Jumps int                           `json:"jumps"`
+	Route []eveSolarSystems.SolarSystem `json:"route"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// errNotFound is turned into a 404
+var errNotFound = errors.New("not found")
+
+// NewHandler the local API, separate from the ESI OAuth callback server.
+func NewHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/openapi.yaml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/yaml")
+		w.Write(openAPISpec)
+	})
+	mux.HandleFunc("/systems", handleSystemSearch)
+	mux.HandleFunc("/systems/", handleSystem)
+	mux.HandleFunc("/stagings", handleStagings)
+	mux.HandleFunc("/stagings/", handleStaging)
+	mux.HandleFunc("/locations", handleLocations)
+	mux.HandleFunc("/locations/history", handleLocationHistory)
+	mux.HandleFunc("/jump-bridges", handleJumpBridges)
+	mux.HandleFunc("/reach", handleReach)
+	mux.HandleFunc("/coverage", handleCoverage)
+	mux.HandleFunc("/matrix", handleMatrix)
+	mux.HandleFunc("/cynos", handleCynos)
+	mux.HandleFunc("/events", handleEvents)
+
+	return guardChanges(mux)
+}
+
+// guardChanges checks requests that change stagings before they reach the handlers, so a web page open in the
+// browser can't post to the API. Changes have to be addressed to the local API, send JSON and carry the token
+// when one is saved.
+func guardChanges(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost && r.Method != http.MethodPut && r.Method != http.MethodDelete {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if origin := r.Header.Get("Origin"); !isLocalAPIHost(r.Host) || (origin != "" && origin != "http://"+r.Host) {
+			writeJSON(w, http.StatusForbidden, errorResponse{Error: "changes are only accepted addressed to the local API"})
+			return
+		}
+		if token := eveSolarSystems.GetLocalAPIToken(); token != "" &&
+			subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "changes need the local API token"})
+			return
+		}
+		// DELETE has no body, browsers can't send it to another site without a preflight the API never answers
+		if r.Method != http.MethodDelete {
+			if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+				writeJSON(w, http.StatusUnsupportedMediaType, errorResponse{Error: "send changes as Content-Type: application/json"})
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isLocalAPIHost true when a request's Host is a loopback address or the address the local API is set to.
+func isLocalAPIHost(host string) bool {
+	if host == eveSolarSystems.GetLocalAPIAddress() {
+		return true
+	}
+	hostname, _, err := net.SplitHostPort(host)
+	if err != nil {
+		hostname = host
+	}
+	switch hostname {
+	case "localhost", "127.0.0.1", "::1":
+		return true
+	}
+	return false
+}
+
+// GET /systems?search=prefix
+func handleSystemSearch(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+	systems := []eveSolarSystems.SolarSystem{}
+	if search := r.URL.Query().Get("search"); search != "" {
+		systems = append(systems, eveSolarSystems.SearchSystems(search)...)
+	}
+	writeJSON(w, http.StatusOK, systems)
+}
+
+// GET /systems/{name}, GET /systems/{name}/stagings?profile=capitals,supers,
+// GET /systems/{name}/gates?within=5, GET /systems/{name}/gates/{to} and GET /systems/{name}/portal?type=titan&mass=2000
+func handleSystem(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+	name, subResource, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/systems/"), "/")
+	solarSystem, found := eveSolarSystems.FindSystemByName(name)
+	if !found {
+		writeError(w, fmt.Errorf("%w: unknown system %q", errNotFound, name))
+		return
+	}
+
+	switch subResource {
+	case "":
+		writeJSON(w, http.StatusOK, SystemInfo{
+			SolarSystem: solarSystem,
+			Sov:         eveSolarSystems.GetSovereigntyHolder(solarSystem.ID),
+		})
+	case "stagings":
+		profiles, err := findProfiles(r.URL.Query().Get("profile"))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, eveSolarSystems.GetRangeResults(solarSystem, profiles))
+	case "portal":
+		portalTypeName := r.URL.Query().Get("type")
+		if portalTypeName == "" {
+			portalTypeName = eveSolarSystems.PortalTypes[0].Name
+		}
+		portalType, found := eveSolarSystems.FindPortalType(portalTypeName)
+		if !found {
+			writeError(w, badRequest(fmt.Errorf("unknown portal type %q", portalTypeName)))
+			return
+		}
+		var mass float64
+		if value := r.URL.Query().Get("mass"); value != "" {
+			var err error
+			if mass, err = strconv.ParseFloat(value, 64); err != nil || mass < 0 {
+				writeError(w, badRequest(fmt.Errorf("mass must be a number of million kg")))
+				return
+			}
+		}
+		writeJSON(w, http.StatusOK, eveSolarSystems.GetPortalLandings(solarSystem, portalType, mass*1e6))
+	case "gates":
+		maxJumps, err := strconv.Atoi(r.URL.Query().Get("within"))
+		if err != nil {
+			writeError(w, badRequest(fmt.Errorf("within must be a number of gate jumps")))
+			return
+		}
+		systems, err := eveSolarSystems.SystemsWithinGates(solarSystem, maxJumps)
+		if err != nil {
+			writeError(w, badRequest(err))
+			return
+		}
+		if systems == nil {
+			systems = []eveSolarSystems.GateDistance{}
+		}
+		writeJSON(w, http.StatusOK, systems)
+	default:
+		toName, isGateRoute := strings.CutPrefix(subResource, "gates/")
+		if !isGateRoute {
+			writeError(w, fmt.Errorf("%w: %s", errNotFound, r.URL.Path))
+			return
+		}
+		to, found := eveSolarSystems.FindSystemByName(toName)
+		if !found {
+			writeError(w, fmt.Errorf("%w: unknown system %q", errNotFound, toName))
+			return
+		}
+		route, err := eveSolarSystems.FindGateRoute(solarSystem, to)
+		if err != nil {
+			writeError(w, fmt.Errorf("%w: %s", errNotFound, err))
+			return
+		}
+		writeJSON(w, http.StatusOK, GateRoute{Jumps: len(route) - 1, Route: route})
+	}
+}
+
+// GET /stagings?list=name and POST /stagings
+func handleStagings(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet, http.MethodPost) {
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		var staging Staging
+		if err := json.NewDecoder(r.Body).Decode(&staging); err != nil {
+			writeError(w, badRequest(err))
+			return
+		}
+		list := listOrDefault(staging.List)
+		solarSystem, err := eveSolarSystems.AddStagingSystem(list, staging.System, staging.Note)
+		if err != nil {
+			writeError(w, badRequest(err))
+			return
+		}
+		writeJSON(w, http.StatusCreated, Staging{List: list, System: solarSystem.Name, Note: staging.Note})
+		return
+	}
+
+	stagings, err := getStagings(r.URL.Query().Get("list"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, stagings)
+}
+
+// GET, PUT and DELETE /stagings/{system}?list=name
+func handleStaging(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet, http.MethodPut, http.MethodDelete) {
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, "/stagings/")
+	list := listOrDefault(r.URL.Query().Get("list"))
+
+	switch r.Method {
+	case http.MethodPut:
+		var staging Staging
+		if err := json.NewDecoder(r.Body).Decode(&staging); err != nil {
+			writeError(w, badRequest(err))
+			return
+		}
+		solarSystem, err := eveSolarSystems.AddStagingSystem(list, name, staging.Note)
+		if err != nil {
+			writeError(w, badRequest(err))
+			return
+		}
+		writeJSON(w, http.StatusOK, Staging{List: list, System: solarSystem.Name, Note: staging.Note})
+	case http.MethodDelete:
+		if err := eveSolarSystems.RemoveStagingSystem(list, name); errors.Is(err, eveSolarSystems.ErrReadOnlyStagingList) {
+			writeError(w, err)
+			return
+		} else if err != nil {
+			writeError(w, fmt.Errorf("%w: %s", errNotFound, err))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		stagings, err := getStagings(list)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		for _, staging := range stagings {
+			if strings.EqualFold(staging.System, name) {
+				writeJSON(w, http.StatusOK, staging)
+				return
+			}
+		}
+		writeError(w, fmt.Errorf("%w: %q is not a staging system in %s", errNotFound, name, list))
+	}
+}
+
+// GET /jump-bridges
+func handleJumpBridges(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+	bridges := []eveSolarSystems.JumpBridge{}
+	writeJSON(w, http.StatusOK, append(bridges, eveSolarSystems.GetJumpBridges()...))
+}
+
+// GET /reach?systems=1DQ1-A,8QT-H4&list=name&profile=capitals&region=Delve&sov=holder
+func handleReach(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+	query := r.URL.Query()
+	var targets []eveSolarSystems.SolarSystem
+	if list := query.Get("list"); list != "" {
+		stagings, err := getStagings(list)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		for _, staging := range stagings {
+			solarSystem, _ := eveSolarSystems.FindSystemByName(staging.System)
+			targets = append(targets, solarSystem)
+		}
+	}
+	for _, name := range strings.Split(query.Get("systems"), ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		solarSystem, found := eveSolarSystems.FindSystemByName(name)
+		if !found {
+			writeError(w, fmt.Errorf("%w: unknown system %q", errNotFound, name))
+			return
+		}
+		targets = append(targets, solarSystem)
+	}
+	profile := query.Get("profile")
+	if profile == "" {
+		profile = "Capitals"
+	}
+	profileName, found := eveSolarSystems.FindShipRange(profile)
+	if !found {
+		writeError(w, badRequest(fmt.Errorf("unknown range profile %q", profile)))
+		return
+	}
+
+	systems, err := eveSolarSystems.GetSystemsInReach(targets, eveSolarSystems.ShipRanges[profileName], eveSolarSystems.ReachFilter{
+		Region: query.Get("region"),
+		Sov:    query.Get("sov"),
+	})
+	if err != nil {
+		writeError(w, badRequest(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, append([]eveSolarSystems.SystemInReach{}, systems...))
+}
+
+// GET /coverage?list=name&profile=capitals
+func handleCoverage(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+	list := listOrDefault(r.URL.Query().Get("list"))
+	if _, found := eveSolarSystems.FindStagingList(list); !found {
+		writeError(w, fmt.Errorf("%w: unknown staging list %q", errNotFound, list))
+		return
+	}
+	profile := r.URL.Query().Get("profile")
+	if profile == "" {
+		profile = "Capitals"
+	}
+	analysis, err := eveSolarSystems.AnalyzeCoverage(list, profile)
+	if err != nil {
+		writeError(w, badRequest(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, analysis)
+}
+
+// GET /matrix?list=name&format=json|csv
+func handleMatrix(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+	list := listOrDefault(r.URL.Query().Get("list"))
+	matrix, err := eveSolarSystems.GetStagingMatrix(list)
+	if err != nil {
+		writeError(w, fmt.Errorf("%w: %s", errNotFound, err))
+		return
+	}
+	switch r.URL.Query().Get("format") {
+	case "", "json":
+		writeJSON(w, http.StatusOK, matrix)
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		eveSolarSystems.WriteStagingMatrixCSV(w, matrix)
+	default:
+		writeError(w, badRequest(fmt.Errorf("unknown format %q, use json or csv", r.URL.Query().Get("format"))))
+	}
+}
+
+// GET /cynos?origin=1DQ1-A&destinations=Tama,Amamake&profile=capitals&friendly=list
+func handleCynos(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+	query := r.URL.Query()
+	origin, found := eveSolarSystems.FindSystemByName(query.Get("origin"))
+	if !found {
+		writeError(w, fmt.Errorf("%w: unknown origin system %q", errNotFound, query.Get("origin")))
+		return
+	}
+	var destinations []eveSolarSystems.SolarSystem
+	for _, name := range strings.Split(query.Get("destinations"), ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		destination, found := eveSolarSystems.FindSystemByName(name)
+		if !found {
+			writeError(w, fmt.Errorf("%w: unknown system %q", errNotFound, name))
+			return
+		}
+		destinations = append(destinations, destination)
+	}
+	var friendly []eveSolarSystems.SolarSystem
+	if list := query.Get("friendly"); list != "" {
+		stagings, err := getStagings(list)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		for _, staging := range stagings {
+			solarSystem, _ := eveSolarSystems.FindSystemByName(staging.System)
+			friendly = append(friendly, solarSystem)
+		}
+	}
+	profile := query.Get("profile")
+	if profile == "" {
+		profile = "Capitals"
+	}
+
+	plan, err := eveSolarSystems.PlanCynos(origin, destinations, profile, friendly)
+	if err != nil {
+		writeError(w, badRequest(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, plan)
+}
+
+// GET /locations
+func handleLocations(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+	locations := []Location{}
+	for _, event := range eveSolarSystems.Tracker.Locations() {
+		solarSystem, _ := eveSolarSystems.FindSystemByID(event.SolarSystemID)
+		locations = append(locations, Location{LocationEvent: event, SolarSystemName: solarSystem.Name})
+	}
+	sort.Slice(locations, func(i, j int) bool {
+		return locations[i].Character < locations[j].Character
+	})
+	writeJSON(w, http.StatusOK, locations)
+}
+
+// GET /locations/history?character=name&days=7
+func handleLocationHistory(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+	var since time.Time
+	if value := r.URL.Query().Get("days"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 1 {
+			writeError(w, badRequest(fmt.Errorf("days %q isn't a positive number", value)))
+			return
+		}
+		since = time.Now().AddDate(0, 0, -days)
+	}
+	writeJSON(w, http.StatusOK, eveSolarSystems.GetLocationHistory(r.URL.Query().Get("character"), since))
+}
+
+// getStagings the stagings in one list, or in every list when list is blank.
+func getStagings(list string) ([]Staging, error) {
+	if strings.TrimSpace(list) != "" {
+		listName, found := eveSolarSystems.FindStagingList(list)
+		if !found {
+			return nil, fmt.Errorf("%w: unknown staging list %q", errNotFound, list)
+		}
+		list = listName
+	}
+
+	stagings := []Staging{}
+	for _, entry := range eveSolarSystems.GetAllStagings() {
+		if list == "" || entry.List == list {
+			stagings = append(stagings, Staging{List: entry.List, System: entry.System, Note: entry.Note})
+		}
+	}
+	return stagings, nil
+}
+
+// listOrDefault the matching list name, new list names are kept as given.
+func listOrDefault(list string) string {
+	if listName, found := eveSolarSystems.FindStagingList(list); found {
+		return listName
+	}
+	return strings.TrimSpace(list)
+}
+
+// findProfiles matches a comma separated list of range profiles, an empty list means every profile.
+func findProfiles(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return eveSolarSystems.ShipRangeNames, nil
+	}
+
+	var profiles []string
+	for _, name := range strings.Split(value, ",") {
+		profileName, found := eveSolarSystems.FindShipRange(name)
+		if !found {
+			return nil, badRequest(fmt.Errorf("unknown range profile %q", name))
+		}
+		profiles = append(profiles, profileName)
+	}
+	return profiles, nil
+}
+
+// allowMethods writes a 405 and returns false when the request method isn't one of methods.
+func allowMethods(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, method := range methods {
+		if r.Method == method {
+			return true
+		}
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+	return false
+}
+
+// requestError an error caused by the request rather than the app.
+type requestError struct {
+	err error
+}
+
+func (e requestError) Error() string {
+	return e.err.Error()
+}
+
+func (e requestError) Unwrap() error {
+	return e.err
+}
+
+func badRequest(err error) error {
+	return requestError{err: err}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var reqErr requestError
+	switch {
+	case errors.Is(err, eveSolarSystems.ErrReadOnlyStagingList):
+		// Subscribed lists only change when synced from their source
+		status = http.StatusConflict
+	case errors.Is(err, errNotFound):
+		status = http.StatusNotFound
+	case errors.As(err, &reqErr):
+		status = http.StatusBadRequest
+	}
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,264 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"github.com/sythe7448/Eve-Sonar/eveSolarSystems"
+	"github.com/sythe7448/Eve-Sonar/internal/testutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testSystems a small hand built map, Alpha to Delta are in a line 3 LY apart and gated in that order
+var testSystems = []testutil.System{
+	{ID: "30000001", Name: "Alpha", X: 0, Sec: -0.4},
+	{ID: "30000002", Name: "Bravo", X: 3, Sec: -0.3},
+	{ID: "30000003", Name: "Charlie", X: 6, Sec: -0.2},
+	{ID: "30000004", Name: "Delta", X: 30, Sec: -0.1},
+}
+
+var testStargates = [][2]string{
+	{"30000001", "30000002"},
+	{"30000002", "30000003"},
+	{"30000003", "30000004"},
+}
+
+func TestMain(m *testing.M) {
+	testutil.Main(m, testSystems, testStargates, eveSolarSystems.WaitForRangeChecks)
+}
+
+// request sends a request to the handler and decodes a JSON response into v when it isn't nil.
+func request(t *testing.T, method, target, body string, v any) *httptest.ResponseRecorder {
+	t.Helper()
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	r.Host = eveSolarSystems.DefaultLocalAPIAddress
+	if body != "" {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	NewHandler().ServeHTTP(w, r)
+	if v != nil {
+		if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+			t.Fatalf("%s %s: decoding %q: %s", method, target, w.Body.String(), err)
+		}
+	}
+	return w
+}
+
+func TestSystemLookup(t *testing.T) {
+	var system SystemInfo
+	if w := request(t, http.MethodGet, "/systems/bravo", "", &system); w.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200", w.Code)
+	}
+	if system.Name != "Bravo" || system.ID != "30000002" {
+		t.Errorf("got %s %s, want Bravo 30000002", system.Name, system.ID)
+	}
+
+	var systems []eveSolarSystems.SolarSystem
+	request(t, http.MethodGet, "/systems?search=ch", "", &systems)
+	if len(systems) != 1 || systems[0].Name != "Charlie" {
+		t.Errorf("search ch got %v, want Charlie", systems)
+	}
+
+	var route GateRoute
+	if w := request(t, http.MethodGet, "/systems/Alpha/gates/Charlie", "", &route); w.Code != http.StatusOK {
+		t.Fatalf("gate route status %d, want 200", w.Code)
+	}
+	if route.Jumps != 2 {
+		t.Errorf("Alpha to Charlie is %d gates, want 2", route.Jumps)
+	}
+}
+
+func TestServer(t *testing.T) {
+	server := httptest.NewServer(NewHandler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/systems/Delta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/json" {
+		t.Fatalf("got %d %s, want 200 application/json", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	var system SystemInfo
+	if err := json.NewDecoder(resp.Body).Decode(&system); err != nil {
+		t.Fatal(err)
+	}
+	if system.Name != "Delta" {
+		t.Errorf("got %s, want Delta", system.Name)
+	}
+}
+
+func TestStagingsInRange(t *testing.T) {
+	for _, system := range []string{"Bravo", "Charlie", "Delta"} {
+		if w := request(t, http.MethodPut, "/stagings/"+system+"?list=Range", `{"note":"test"}`, nil); w.Code != http.StatusOK {
+			t.Fatalf("adding %s: status %d", system, w.Code)
+		}
+	}
+
+	var results []eveSolarSystems.RangeResult
+	if w := request(t, http.MethodGet, "/systems/Alpha/stagings?profile=capitals", "", &results); w.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200", w.Code)
+	}
+	if len(results) != 1 || results[0].Profile != "Capitals" {
+		t.Fatalf("got %+v, want only Capitals", results)
+	}
+	// Other tests add stagings to their own lists
+	var names []string
+	for _, staging := range results[0].Stagings {
+		if staging.List != "Range" {
+			continue
+		}
+		names = append(names, staging.System.Name)
+		if staging.System.Name == "Charlie" && staging.GateJumps != 2 {
+			t.Errorf("Charlie is %d gates away, want 2", staging.GateJumps)
+		}
+	}
+	if strings.Join(names, ",") != "Bravo,Charlie" {
+		t.Errorf("in Capitals range got %v, want Bravo and Charlie", names)
+	}
+}
+
+func TestStagingStatusCodes(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		status int
+	}{
+		{"add", http.MethodPost, "/stagings", `{"list":"Crud","system":"alpha","note":"home"}`, http.StatusCreated},
+		{"get", http.MethodGet, "/stagings/Alpha?list=Crud", "", http.StatusOK},
+		{"update", http.MethodPut, "/stagings/Alpha?list=Crud", `{"note":"moved"}`, http.StatusOK},
+		{"unknown system", http.MethodPost, "/stagings", `{"list":"Crud","system":"Nowhere"}`, http.StatusBadRequest},
+		{"malformed JSON", http.MethodPost, "/stagings", `{"system":`, http.StatusBadRequest},
+		{"missing staging", http.MethodGet, "/stagings/Bravo?list=Crud", "", http.StatusNotFound},
+		{"unknown list", http.MethodGet, "/stagings?list=Nope", "", http.StatusNotFound},
+		{"delete", http.MethodDelete, "/stagings/Alpha?list=Crud", "", http.StatusNoContent},
+		{"delete again", http.MethodDelete, "/stagings/Alpha?list=Crud", "", http.StatusNotFound},
+		{"wrong method", http.MethodPatch, "/stagings", "", http.StatusMethodNotAllowed},
+		{"unknown system lookup", http.MethodGet, "/systems/Nowhere", "", http.StatusNotFound},
+		{"bad profile", http.MethodGet, "/systems/Alpha/stagings?profile=rowboat", "", http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if w := request(t, test.method, test.target, test.body, nil); w.Code != test.status {
+				t.Errorf("status %d, want %d: %s", w.Code, test.status, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestSubscribedListConflict(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "shared.txt")
+	if err := os.WriteFile(source, []byte("Charlie\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := eveSolarSystems.Subscribe("Shared", source, "names", 0); err != nil {
+		t.Fatal(err)
+	}
+	if w := request(t, http.MethodPost, "/stagings", `{"list":"Shared","system":"Alpha"}`, nil); w.Code != http.StatusConflict {
+		t.Errorf("adding to a subscribed list: status %d, want 409", w.Code)
+	}
+	if w := request(t, http.MethodDelete, "/stagings/Charlie?list=Shared", "", nil); w.Code != http.StatusConflict {
+		t.Errorf("removing from a subscribed list: status %d, want 409", w.Code)
+	}
+}
+
+func TestGuardChanges(t *testing.T) {
+	const body = `{"list":"Guarded","system":"Bravo"}`
+	tests := []struct {
+		name    string
+		method  string
+		host    string
+		headers map[string]string
+		status  int
+	}{
+		{"local", http.MethodPost, "localhost:8081", map[string]string{"Content-Type": "application/json; charset=utf-8"}, http.StatusCreated},
+		{"loopback IP", http.MethodPost, "127.0.0.1:8081", map[string]string{"Content-Type": "application/json"}, http.StatusCreated},
+		{"other host", http.MethodPost, "evil.example:8081", map[string]string{"Content-Type": "application/json"}, http.StatusForbidden},
+		{"other origin", http.MethodPost, "localhost:8081", map[string]string{"Content-Type": "application/json", "Origin": "https://evil.example"}, http.StatusForbidden},
+		{"same origin", http.MethodPost, "localhost:8081", map[string]string{"Content-Type": "application/json", "Origin": "http://localhost:8081"}, http.StatusCreated},
+		{"form post", http.MethodPost, "localhost:8081", map[string]string{"Content-Type": "text/plain"}, http.StatusUnsupportedMediaType},
+		{"no content type", http.MethodPut, "localhost:8081", nil, http.StatusUnsupportedMediaType},
+		{"delete from other host", http.MethodDelete, "evil.example", nil, http.StatusForbidden},
+		{"reads from anywhere", http.MethodGet, "evil.example", nil, http.StatusOK},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			target := "/stagings"
+			if test.method != http.MethodPost {
+				target = "/stagings/Bravo?list=Guarded"
+			}
+			r := httptest.NewRequest(test.method, target, strings.NewReader(body))
+			r.Host = test.host
+			for key, value := range test.headers {
+				r.Header.Set(key, value)
+			}
+			w := httptest.NewRecorder()
+			NewHandler().ServeHTTP(w, r)
+			if w.Code != test.status {
+				t.Errorf("status %d, want %d: %s", w.Code, test.status, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestToken(t *testing.T) {
+	token, err := eveSolarSystems.NewLocalAPIToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer eveSolarSystems.ClearLocalAPIToken()
+
+	if w := request(t, http.MethodPut, "/stagings/Delta?list=Token", `{}`, nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("without the token: status %d, want 401", w.Code)
+	}
+	r := httptest.NewRequest(http.MethodPut, "/stagings/Delta?list=Token", strings.NewReader(`{}`))
+	r.Host = eveSolarSystems.DefaultLocalAPIAddress
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	NewHandler().ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Errorf("with the token: status %d, want 200", w.Code)
+	}
+	if w := request(t, http.MethodGet, "/stagings/Delta?list=Token", "", nil); w.Code != http.StatusOK {
+		t.Errorf("reading without the token: status %d, want 200", w.Code)
+	}
+}
+
+func TestLocations(t *testing.T) {
+	source := eveSolarSystems.NewManualLocationSource()
+	eveSolarSystems.Tracker.AddSource(source)
+	defer eveSolarSystems.Tracker.RemoveSource(source.Name())
+	submitted := time.Now()
+	source.Submit("30000003")
+
+	// The tracker hands events on in its own goroutine, and may still have Charlie from an earlier run
+	deadline := time.Now().Add(time.Second * 2)
+	for {
+		var locations []Location
+		request(t, http.MethodGet, "/locations", "", &locations)
+		if len(locations) == 1 && locations[0].SolarSystemName == "Charlie" && locations[0].Source == eveSolarSystems.SourceManual &&
+			!locations[0].Time.Before(submitted) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got %+v, want the manual location in Charlie", locations)
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	if w := request(t, http.MethodPost, "/locations", "{}", nil); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST /locations: status %d, want 405", w.Code)
+	}
+	if w := request(t, http.MethodGet, "/locations/history?days=soon", "", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("bad days: status %d, want 400", w.Code)
+	}
+}
@@ -0,0 +1,72 @@
+// Package testutil sets up the app folder the packages' tests run in.
+package testutil
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// MetersPerLightYear test systems are laid out in light years and saved in meters like the SDE.
+const MetersPerLightYear = 9460730472580800
+
+// System a hand built solar system, laid out in light years across X and Y.
+type System struct {
+	ID, Name string
+	X, Y     float64
+	Sec      float64
+}
+
+// Main runs a package's tests in a new temporary app folder holding the systems and stargates, so they get their
+// own DB and data files. Once the tests are done it calls wait, to let background work finish with the DB, then
+// removes the folder.
+func Main(m *testing.M, systems []System, stargates [][2]string, wait func()) {
+	dir, err := os.MkdirTemp("", "eve-sonar-test")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := WriteData(filepath.Join(dir, "eveSolarSystems"), systems, stargates); err != nil {
+		log.Fatal(err)
+	}
+	workingDir, err := os.Getwd()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		log.Fatal(err)
+	}
+
+	code := m.Run()
+	if wait != nil {
+		wait()
+	}
+	if err := os.Chdir(workingDir); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		log.Println("Error removing the test folder:", err)
+	}
+	os.Exit(code)
+}
+
+// WriteData writes the systems and stargates CSVs the eveSolarSystems package reads into dir.
+func WriteData(dir string, systems []System, stargates [][2]string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	systemLines := []string{"solarSystemID,solarSystemName,x,y,z,security"}
+	for _, system := range systems {
+		systemLines = append(systemLines, fmt.Sprintf("%s,%s,%g,%g,0,%g", system.ID, system.Name, system.X*MetersPerLightYear, system.Y*MetersPerLightYear, system.Sec))
+	}
+	if err := os.WriteFile(filepath.Join(dir, "eveSolarSystems.csv"), []byte(strings.Join(systemLines, "\n")+"\n"), 0644); err != nil {
+		return err
+	}
+	stargateLines := []string{"fromSolarSystemID,toSolarSystemID"}
+	for _, gate := range stargates {
+		stargateLines = append(stargateLines, gate[0]+","+gate[1])
+	}
+	return os.WriteFile(filepath.Join(dir, "eveStargates.csv"), []byte(strings.Join(stargateLines, "\n")+"\n"), 0644)
+}
@@ -1,12 +1,24 @@
+//go:build gui
+
 package main
 
 import (
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/theme"
+	"github.com/sythe7448/Eve-Sonar/cli"
 	"github.com/sythe7448/Eve-Sonar/eveSolarSystems"
+	"github.com/sythe7448/Eve-Sonar/httpapi"
+	"os"
 )
 
 func main() {
+	// Any subcommand runs headless, no subcommand opens the app
+	if len(os.Args) > 1 && cli.IsCommand(os.Args[1]) {
+		os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr))
+	}
+
+	eveSolarSystems.LocalAPIHandler = httpapi.NewHandler()
+
 	trackerApp := app.New()
 	trackerApp.Settings().SetTheme(theme.DarkTheme())
 	trackerWindow := trackerApp.NewWindow("Eve Sonar")